@@ -0,0 +1,685 @@
+/**
+ * MIT License
+ *
+ * Copyright (c) 2025 Viki (VN - initials of my first and last name)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ *
+ * Contact: contact@viki.design
+ * Website: https://www.viki.design
+ *
+ */
+
+package main
+
+/*
+  Command mode:
+
+    `:` opens a command line below the table ( vim style ). Commands are kept
+    in a registry, so new features only have to register themselves:
+
+        :w [file]        write ( to another file: write a copy )
+        :saveas <file>   write to another file and keep editing it
+        :q  :q!          quit ( :q! discards unsaved changes )
+        :wq  :x          write and quit
+        :e[!] <file>     open another csv file
+        :goto <row> [col]  or just  :<row>
+        :set [option[=value]]
+
+    Tab completes command names, file names and option names. Up / Down walk
+    through the commands entered before.
+*/
+
+import (
+    "fmt"
+    "os"
+    "path/filepath"
+    "sort"
+    "strconv"
+    "strings"
+
+    "github.com/gdamore/tcell/v2"
+    "github.com/rivo/tview"
+)
+
+type command struct {
+    name    string
+    aliases []string
+    args    string // usage, e.g. "<file>"
+    help    string
+
+    // What to complete after the command name: "file", "option" or ""
+    complete string
+
+    run func(bang bool, args string) error
+}
+
+// Options changed with :set
+type option struct {
+    name string
+    help string
+
+    // Boolean options can be set with :set name / :set noname
+    boolean bool
+
+    get func() string
+    set func(value string) error
+}
+
+var (
+    commands []*command
+    options  []*option
+
+    commandLine    *tview.InputField
+    commandHistory []string
+    historyPos     int
+)
+
+func registerCommand(c *command) {
+    commands = append(commands, c)
+}
+
+func registerOption(o *option) {
+    options = append(options, o)
+}
+
+func findCommand(name string) *command {
+    for _, c := range commands {
+        if c.name == name {
+            return c
+        }
+        for _, alias := range c.aliases {
+            if alias == name {
+                return c
+            }
+        }
+    }
+    return nil
+}
+
+func findOption(name string) *option {
+    for _, o := range options {
+        if o.name == name {
+            return o
+        }
+    }
+    return nil
+}
+
+// Split the arguments of a command at spaces, "double quotes" keep spaces together
+func splitArgs(args string) []string {
+    var fields []string
+    var current strings.Builder
+    inQuotes := false
+    hasField := false
+
+    for _, r := range args {
+        switch {
+        case r == '"':
+            inQuotes = !inQuotes
+            hasField = true
+        case (r == ' ' || r == '\t') && !inQuotes:
+            if hasField {
+                fields = append(fields, current.String())
+                current.Reset()
+                hasField = false
+            }
+        default:
+            current.WriteRune(r)
+            hasField = true
+        }
+    }
+    if hasField {
+        fields = append(fields, current.String())
+    }
+    return fields
+}
+
+// "wq! file.csv" -> "wq", true, "file.csv"
+func parseCommandLine(line string) (string, bool, string) {
+    line = strings.TrimSpace(line)
+
+    end := 0
+    for end < len(line) && (line[end] >= 'a' && line[end] <= 'z' || line[end] >= 'A' && line[end] <= 'Z' || line[end] >= '0' && line[end] <= '9') {
+        end++
+    }
+    name := line[:end]
+    rest := line[end:]
+
+    bang := false
+    if strings.HasPrefix(rest, "!") {
+        bang = true
+        rest = rest[1:]
+    }
+    return name, bang, strings.TrimSpace(rest)
+}
+
+func executeCommand(line string) error {
+    name, bang, args := parseCommandLine(line)
+    if name == "" {
+        return nil
+    }
+
+    // :<row> jumps to a row
+    if _, err := strconv.Atoi(name); err == nil {
+        return cmdGoto(false, strings.TrimSpace(name+" "+args))
+    }
+
+    c := findCommand(name)
+    if c == nil {
+        return fmt.Errorf("unknown command: %s", name)
+    }
+    return c.run(bang, args)
+}
+
+func commandLineInit() {
+    commandLine = tview.NewInputField().
+        SetLabel(":")
+
+    commandLine.SetDoneFunc(func(key tcell.Key) {
+        line := commandLine.GetText()
+        closeCommandLine()
+
+        if key != tcell.KeyEnter || strings.TrimSpace(line) == "" {
+            return
+        }
+
+        commandHistory = append(commandHistory, line)
+        err := executeCommand(line)
+        if err != nil {
+            showMessage("Error: %v", err)
+        }
+    })
+
+    commandLine.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+        switch event.Key() {
+        case tcell.KeyTab:
+            completeCommandLine()
+            return nil
+        case tcell.KeyUp:
+            if historyPos > 0 {
+                historyPos--
+                commandLine.SetText(commandHistory[historyPos])
+            }
+            return nil
+        case tcell.KeyDown:
+            if historyPos < len(commandHistory)-1 {
+                historyPos++
+                commandLine.SetText(commandHistory[historyPos])
+            } else {
+                historyPos = len(commandHistory)
+                commandLine.SetText("")
+            }
+            return nil
+        case tcell.KeyBackspace, tcell.KeyBackspace2:
+            // Backspace on an empty command line leaves command mode ( like vim )
+            if commandLine.GetText() == "" {
+                closeCommandLine()
+                return nil
+            }
+        }
+        return event
+    })
+
+    registerBuiltinCommands()
+}
+
+func openCommandLine() {
+    openCommandLineWith("")
+}
+
+func openCommandLineWith(text string) {
+    editing = true
+    historyPos = len(commandHistory)
+    commandLine.SetText(text)
+    flex.AddItem(commandLine, 3, 0, true)
+    app.SetFocus(commandLine)
+}
+
+func closeCommandLine() {
+    editing = false
+    commandLine.SetText("")
+    flex.RemoveItem(commandLine)
+    app.SetFocus(table)
+}
+
+// Tab completion
+
+func completeCommandLine() {
+    text := commandLine.GetText()
+    name, bang, args := parseCommandLine(text)
+
+    var candidates []string
+    var prefix string
+
+    if !strings.ContainsAny(text, " ") && !bang {
+        // Completing the command name
+        prefix = name
+        for _, c := range commands {
+            for _, n := range append([]string{c.name}, c.aliases...) {
+                if strings.HasPrefix(n, prefix) {
+                    candidates = append(candidates, n)
+                }
+            }
+        }
+        sort.Strings(candidates)
+        applyCompletion(candidates, prefix, func(s string) string { return s + " " })
+        return
+    }
+
+    c := findCommand(name)
+    if c == nil {
+        return
+    }
+
+    head := name
+    if bang {
+        head += "!"
+    }
+
+    switch c.complete {
+    case "file":
+        prefix = args
+        candidates = completeFileName(args)
+        applyCompletion(candidates, prefix, func(s string) string { return head + " " + s })
+    case "option":
+        prefix = args
+        for _, o := range options {
+            if strings.HasPrefix(o.name, prefix) {
+                candidates = append(candidates, o.name)
+            }
+        }
+        applyCompletion(candidates, prefix, func(s string) string { return head + " " + s })
+    }
+}
+
+// One candidate is taken as it is, several are completed up to their common
+// prefix and listed in the status line
+func applyCompletion(candidates []string, prefix string, line func(string) string) {
+    if len(candidates) == 0 {
+        return
+    }
+    if len(candidates) == 1 {
+        completed := line(candidates[0])
+        // Directories stay open for the next Tab
+        if strings.HasSuffix(candidates[0], string(os.PathSeparator)) {
+            completed = strings.TrimSuffix(completed, " ")
+        }
+        commandLine.SetText(completed)
+        return
+    }
+
+    common := candidates[0]
+    for _, c := range candidates[1:] {
+        for !strings.HasPrefix(c, common) {
+            common = common[:len(common)-1]
+        }
+    }
+    if len(common) > len(prefix) {
+        commandLine.SetText(strings.TrimSuffix(line(common), " "))
+    }
+    showMessage("%s", strings.Join(candidates, "  "))
+}
+
+func completeFileName(prefix string) []string {
+    dir, base := filepath.Split(prefix)
+    readDir := dir
+    if readDir == "" {
+        readDir = "."
+    }
+
+    entries, err := os.ReadDir(readDir)
+    if err != nil {
+        return nil
+    }
+
+    var names []string
+    for _, e := range entries {
+        if !strings.HasPrefix(e.Name(), base) {
+            continue
+        }
+        // Hidden files only when asked for
+        if strings.HasPrefix(e.Name(), ".") && !strings.HasPrefix(base, ".") {
+            continue
+        }
+        name := dir + e.Name()
+        if e.IsDir() {
+            name += string(os.PathSeparator)
+        }
+        names = append(names, name)
+    }
+    sort.Strings(names)
+    return names
+}
+
+// Built in commands
+
+func registerBuiltinCommands() {
+    registerCommand(&command{
+        name: "write", aliases: []string{"w"}, args: "[file]", complete: "file",
+        help: "Save ( to another file: write a copy and keep editing this one )",
+        run:  cmdWrite,
+    })
+    registerCommand(&command{
+        name: "saveas", args: "<file>", complete: "file",
+        help: "Save to another file and keep editing that file",
+        run:  cmdSaveAs,
+    })
+    registerCommand(&command{
+        name: "quit", aliases: []string{"q"},
+        help: "Quit ( q! discards unsaved changes )",
+        run:  cmdQuit,
+    })
+    registerCommand(&command{
+        name: "wq", aliases: []string{"x"}, args: "[file]", complete: "file",
+        help: "Save and quit",
+        run:  cmdWriteQuit,
+    })
+    registerCommand(&command{
+        name: "edit", aliases: []string{"e"}, args: "<file>", complete: "file",
+        help: "Open another csv file ( e! discards unsaved changes )",
+        run:  cmdEdit,
+    })
+    registerCommand(&command{
+        name: "goto", aliases: []string{"g"}, args: "<row> [col]",
+        help: "Jump to a row ( and column: number or header name ), :<row> works too",
+        run:  cmdGoto,
+    })
+    registerCommand(&command{
+        name: "set", args: "[option[=value]]", complete: "option",
+        help: "Show or change options ( :set name, :set noname, :set name=value, :set name? )",
+        run:  cmdSet,
+    })
+    registerCommand(&command{
+        name: "undo", aliases: []string{"u"},
+        help: "Undo last change",
+        run:  func(bang bool, args string) error { undo(); return nil },
+    })
+    registerCommand(&command{
+        name: "redo",
+        help: "Redo last undone change",
+        run:  func(bang bool, args string) error { redo(); return nil },
+    })
+    registerCommand(&command{
+        name: "insrow",
+        help: "Insert a row below the selected row",
+        run:  func(bang bool, args string) error { insertRowBelow(); return nil },
+    })
+    registerCommand(&command{
+        name: "inscol",
+        help: "Insert a column right of the selected column",
+        run:  func(bang bool, args string) error { insertColumnRight(); return nil },
+    })
+    registerCommand(&command{
+        name: "delrow",
+        help: "Delete the selected row ( no confirmation, no copy to .completed.csv )",
+        run:  func(bang bool, args string) error { deleteSelectedRow(); return nil },
+    })
+    registerCommand(&command{
+        name: "delcol",
+        help: "Delete the selected column ( no confirmation )",
+        run:  func(bang bool, args string) error { deleteSelectedCol(); return nil },
+    })
+    registerCommand(&command{
+        name: "clear",
+        help: "Clear the selected cell",
+        run:  func(bang bool, args string) error { clearCell(); return nil },
+    })
+
+    registerOption(&option{
+        name: "undodepth",
+        help: "Number of changes that can be undone",
+        get:  func() string { return strconv.Itoa(undoDepth) },
+        set: func(value string) error {
+            n, err := strconv.Atoi(value)
+            if err != nil || n < 1 {
+                return fmt.Errorf("undodepth needs a number > 0")
+            }
+            undoDepth = n
+            trimUndoStack()
+            return nil
+        },
+    })
+}
+
+func cmdWrite(bang bool, args string) error {
+    files := splitArgs(args)
+    if len(files) == 0 {
+        saveCSV(inputFile)
+        return nil
+    }
+
+    path := files[0]
+    if path == inputFile {
+        saveCSV(inputFile)
+        return nil
+    }
+    if _, err := os.Stat(path); err == nil && !bang {
+        return fmt.Errorf("%s exists ( add ! to overwrite )", path)
+    }
+    warning, err := writeCSVFile(path)
+    if err != nil {
+        return err
+    }
+    showMessage("Written %s%s", path, warning)
+    return nil
+}
+
+func cmdSaveAs(bang bool, args string) error {
+    files := splitArgs(args)
+    if len(files) == 0 {
+        return fmt.Errorf("saveas needs a file name")
+    }
+    path := files[0]
+    if _, err := os.Stat(path); err == nil && path != inputFile && !bang {
+        return fmt.Errorf("%s exists ( add ! to overwrite )", path)
+    }
+    saveAsFile(path)
+    return nil
+}
+
+func cmdQuit(bang bool, args string) error {
+    if doc.Dirty() && !bang {
+        return fmt.Errorf("unsaved changes ( add ! to discard them or use :wq )")
+    }
+    if doc.Dirty() {
+        journalDiscard()
+    }
+    closeCurrentTab()
+    return nil
+}
+
+func cmdWriteQuit(bang bool, args string) error {
+    files := splitArgs(args)
+    if len(files) > 0 {
+        if saveAsFile(files[0]) {
+            closeCurrentTab()
+        }
+        return nil
+    }
+
+    // Nothing changed: nothing to write
+    if doc.Dirty() && !saveCSV(inputFile) {
+        return nil
+    }
+    closeCurrentTab()
+    return nil
+}
+
+func cmdEdit(bang bool, args string) error {
+    files := splitArgs(args)
+    if len(files) == 0 {
+        return fmt.Errorf("edit needs a file name")
+    }
+    if doc.Dirty() && !bang {
+        return fmt.Errorf("unsaved changes ( add ! to discard them or :w first )")
+    }
+    return openFile(files[0])
+}
+
+// Replace the current file with another one
+func openFile(path string) error {
+    // Stop the loader of the current file before its state is replaced
+    loadGeneration.Add(1)
+
+    oldFile := inputFile
+    if err := loadCSV(path); err != nil {
+        inputFile = oldFile
+        return err
+    }
+
+    // The edits of the old file are abandoned, a journal of a previous
+    // session that waits for recovery stays
+    journalClose()
+    if journalStarted && !journalPending {
+        os.Remove(getJournalPath(oldFile))
+    }
+
+    resetFileState()
+
+    startLoader()
+    table.ScrollToBeginning()
+    refreshTable()
+    // The errors of the keymap file are more important
+    if len(keymapErrors) == 0 {
+        showMessage("Opened %s", path)
+    }
+    return nil
+}
+
+// State of the file before, after loadCSV read another one
+func resetFileState() {
+    filterActive = false
+    filterText = ""
+    viewRows = nil
+
+    undoStack = nil
+    redoStack = nil
+    savedDepth = 0
+    selectedRow = 0
+    selectedCol = 0
+    colWidths = nil
+    loadCSVConfig()
+    loadKeymap()
+}
+
+func cmdGoto(bang bool, args string) error {
+    fields := splitArgs(args)
+    if len(fields) == 0 {
+        return fmt.Errorf("goto needs a row number")
+    }
+
+    row, err := strconv.Atoi(fields[0])
+    if err != nil {
+        return fmt.Errorf("bad row number: %s", fields[0])
+    }
+    if row < 0 {
+        row = 0
+    }
+    if row > len(data)-1 {
+        row = len(data) - 1
+    }
+
+    col := selectedCol
+    if len(fields) > 1 {
+        col, err = columnIndex(fields[1])
+        if err != nil {
+            return err
+        }
+    }
+
+    selectedRow = row
+    selectedCol = col
+    refreshTable()
+    return nil
+}
+
+// Column by number ( 1 based, as in the status line ) or by header name
+func columnIndex(name string) (int, error) {
+    if n, err := strconv.Atoi(name); err == nil {
+        if n < 1 || n > numCols {
+            return 0, fmt.Errorf("no column %d", n)
+        }
+        return n - 1, nil
+    }
+    for c := 0; c < numCols; c++ {
+        if strings.EqualFold(strings.TrimSpace(cellText(0, c)), name) {
+            return c, nil
+        }
+    }
+    return 0, fmt.Errorf("no column named %q", name)
+}
+
+func cmdSet(bang bool, args string) error {
+    fields := splitArgs(args)
+    if len(fields) == 0 {
+        var values []string
+        for _, o := range options {
+            values = append(values, o.name+"="+o.get())
+        }
+        showMessage("%s", strings.Join(values, "  "))
+        return nil
+    }
+
+    for _, field := range fields {
+        if err := setOption(field); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+func setOption(field string) error {
+    if strings.HasSuffix(field, "?") {
+        o := findOption(strings.TrimSuffix(field, "?"))
+        if o == nil {
+            return fmt.Errorf("unknown option: %s", field)
+        }
+        showMessage("%s=%s", o.name, o.get())
+        return nil
+    }
+
+    name, value, hasValue := strings.Cut(field, "=")
+    o := findOption(name)
+
+    if o == nil && !hasValue && strings.HasPrefix(name, "no") {
+        o = findOption(strings.TrimPrefix(name, "no"))
+        if o != nil && o.boolean {
+            return o.set("false")
+        }
+    }
+    if o == nil {
+        return fmt.Errorf("unknown option: %s", name)
+    }
+
+    if !hasValue {
+        if o.boolean {
+            return o.set("true")
+        }
+        showMessage("%s=%s", o.name, o.get())
+        return nil
+    }
+    if err := o.set(value); err != nil {
+        return err
+    }
+    showMessage("%s=%s", o.name, o.get())
+    return nil
+}
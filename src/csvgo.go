@@ -39,11 +39,10 @@ package main
 
 /*
 Tasks:
-    1. make 3 versions of data_prev_prev, data_prev data_curr ( z move to prev data y move to next data)
-    2. Make 1 space after last row ( don´t fill any row - even if you have space ( this is for command area ))
+    1. Make 1 space after last row ( don´t fill any row - even if you have space ( this is for command area ))
 */
 import (
-	"encoding/csv"
+	"flag"
 	"fmt"
 	"log"
 	"os"
@@ -51,9 +50,12 @@ import (
     "strconv"
 	"strings"
     "path/filepath"
+    "sort"
 	"github.com/atotto/clipboard"
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
+
+    "neoviki_spreadsheet/modules_neoviki/csvio"
 )
 
 var (
@@ -83,24 +85,55 @@ var (
     
     screenHeight int
     screenWidth int
+
+    // System clipboard, the UI tests ( ui_test.go ) use one of their own
+    clipboardWrite = clipboard.WriteAll
+    clipboardRead  = clipboard.ReadAll
 )
 
 const defaultColWidth = 10
 
+// Rows of the table on screen ( one of them goes to the tab bar when several files are open )
+const tableHeight = 33
 
-func createNewPage(pageElementFlex *tview.Flex) {
-    pageCount:=1
-	pageID := strconv.Itoa(pageCount)
+
+// Every file ( tab ) has a page of its own, see tabs.go
+func createNewPage(pageID string, pageElementFlex *tview.Flex) {
 	pages.AddAndSwitchToPage(pageID, pageElementFlex, true)
 }
 
 func argParse(){
-	if len(os.Args) < 2 {
-		fmt.Println("Usage: go run main.go <csv-file>")
+    flag.IntVar(&undoDepth, "undo-depth", defaultUndoDepth, "number of edits that can be undone")
+    dialectFlags()
+    flag.BoolVar(&strictParse, "strict", false, "stop at the first malformed row ( the file is read only then )")
+    flag.BoolVar(&stdoutFlag, "stdout", false, "write the result to stdout on exit instead of saving the file")
+    flag.Usage = func() {
+        fmt.Println("Usage: csvgo [options] <csv-file> [<csv-file> ...]   ( - reads stdin, several files open as tabs )")
+        flag.PrintDefaults()
+    }
+    flag.Parse()
+
+	if flag.NArg() < 1 {
+        flag.Usage()
 		os.Exit(1)
 	}
     
-    inputFile=os.Args[1]
+    inputFile=flag.Arg(0)
+    inputFiles=flag.Args()
+    if stdoutFlag && len(inputFiles) > 1 {
+        fmt.Println("Error: -stdout edits one file only")
+        os.Exit(1)
+    }
+}
+
+func flagIsSet(name string) bool {
+    set := false
+    flag.Visit(func(f *flag.Flag) {
+        if f.Name == name {
+            set = true
+        }
+    })
+    return set
 }
 
 func uiInit(){
@@ -130,11 +163,18 @@ func pageInit(){
 
 func tableInit(){
 	table = tview.NewTable()
+    table.SetContent(&tableContent{})
     //Freeze ( rows,cols) : 1 - row0 will be frozen, 2-row0 and row1 will be frozen
     table.SetFixed(1, 2)
     //able to select (row,col)
     table.SetSelectable(true, true)
     table.SetBorders(true)
+    table.SetSelectionChangedFunc(func(row, col int) {
+        // Keep the cursor in sync when the selection is moved with the mouse
+        selectedRow = dataRowOf(row)
+        selectedCol = col
+        updateStatusBar()
+    })
 }	
 
 func inputTextBoxInit(){
@@ -159,17 +199,16 @@ func flexInit(){
 func flexAddTable(){
     //flex.AddItem(table, 0, 1, true)   // table fills available space
 
+    // Tab bar, no height until a second file is open ( see layoutTabBar )
+    flex.AddItem(tabBar, 0, 0, false)
+
     //fillRow := screenHeight-5  // skip 2 rows ≈ 1 cm
-    fillRow := 33  // skip 2 rows ≈ 1 cm
+    fillRow := tableHeight  // skip 2 rows ≈ 1 cm
     fillCol := 1 // Utilize full screen width for column
     flex.AddItem(table, fillRow, fillCol, true)   // table fills available space
 
-    bottomBorder := tview.NewBox().
-    SetBorder(false).
-    SetBackgroundColor(tcell.ColorWhite) // looks like a thick line
-
-
-    flex.AddItem(bottomBorder, 1, 0, false) // simulate 1-line border
+    // White status line - looks like a thick line below the table
+    flex.AddItem(statusBar, 1, 0, false)
 
 }
 
@@ -181,25 +220,84 @@ func flexRemoveInputTextBox(){
     flex.RemoveItem(inputField)
 }
 
-func loadCSV() {
-	f, err := os.Open(inputFile)
-	if err != nil {
-        fmt.Println("Error: opening csv file")
-		os.Exit(1)
-	}
+// Ask for a line of text below the table ( file name etc ), done is called on Enter only
+func promptInput(label string, text string, done func(string)) {
+    prompt := tview.NewInputField().
+        SetLabel(label).
+        SetText(text)
 
-	defer f.Close()
+    prompt.SetDoneFunc(func(key tcell.Key) {
+        flex.RemoveItem(prompt)
+        app.SetFocus(table)
+        if key == tcell.KeyEnter {
+            done(strings.TrimSpace(prompt.GetText()))
+        }
+    })
 
-	r := csv.NewReader(f)
-	data, err = r.ReadAll()
-	if err != nil {
-		log.Fatal(err)
-        fmt.Println("Error: reading csv file")
-		os.Exit(1)
+    flex.AddItem(prompt, 3, 0, true)
+    app.SetFocus(prompt)
+}
+
+// Open the csv file and read the header row, the rest is read in the background ( see loader.go ).
+// Nothing is changed when the file can not be opened.
+func loadCSV(path string) error {
+    f := os.Stdin
+    if path != stdinPath {
+        var err error
+        if f, err = os.Open(path); err != nil {
+            return err
+        }
+    }
+
+    var size int64
+    info, err := f.Stat()
+    if err == nil {
+        size = info.Size()
+    }
+
+    loadReadBytes.Store(0)
+    raw := bufio.NewReaderSize(&countingReader{r: f}, csvio.SniffSize)
+
+    // Peek does not consume, the readers start at the beginning of the file
+    rawSample, _ := raw.Peek(csvio.SniffSize)
+    enc, err := detectEncoding(path, rawSample)
+    if err != nil {
+        f.Close()
+        return err
+    }
+
+    // Everything after this point is UTF-8
+    br := bufio.NewReaderSize(csvio.NewDecoder(raw, enc), csvio.SniffSize)
+    sample, _ := br.Peek(csvio.SniffSize)
+    d, err := detectDialect(path, sample)
+    if err != nil {
+        f.Close()
+        return err
+    }
+    d.Encoding = enc
+
+	r := csvio.NewReader(br, d)
+    r.Lenient = !strictParse
+	header, format, err := r.ReadRow()
+    issues := parseIssuesOf(header, format, err, len(header))
+	if err != nil && issues == nil {
+        f.Close()
+		return err
 	}
+    d.BOM = r.BOM()
 
-    numCols = len(data[0])
-    numRows = len(data)
+    inputFile = path
+    // Without a header the first row is data, row 0 gets labels ( see header.go )
+    openDocument(d, header, format)
+    parseIssues = nil
+    headerChoiceChanged = false
+    quoteIssues = make(map[*csvio.RowFormat]bool)
+    addParseIssues(issues)
+
+    loadTotalBytes = size
+    loadFile = f
+    loadReader = r
+    return nil
 }
 
 func getColWidth(col_nr int) int {
@@ -210,66 +308,64 @@ func getColWidth(col_nr int) int {
     return defaultColWidth
 }
 
-func renderTableHeader(){
-    // Header row
-    for c := 0; c < numCols; c++ {
-        w := getColWidth(c)
-        text:=wrapText(data[0][c], w)
+// Text of a cell, rows may be shorter than the header
+func cellText(r int, c int) string {
+    if c < len(data[r]) {
+        return data[r][c]
+    }
+    return ""
+}
 
-        // For last column don´t wrap
-        if c == numCols-1 {
-            text=data[0][c]
-        }
-        cell := tview.NewTableCell(text)
-        cell.SetTextColor(tcell.ColorYellow)
-        cell.SetSelectable(true)
-        cell.SetMaxWidth(w)
-        cell.SetExpansion(0)
-
-        // Only last column expands
-        if c == numCols-1 {
-            cell.SetExpansion(1)
-        }
+func tableHeaderCell(c int) *tview.TableCell {
+    w := getColWidth(c)
+    text:=wrapText(cellText(0, c), w)
 
-        //Add the cell content to table
-        table.SetCell(0, c, cell)
-        //log.Printf("Col [%d] Width : %d", c, w)
-    }
-}
-
-func renderTableBody(){
-    // Data rows
-    for r := 1; r < numRows; r++ {
-        for c := 0; c < numCols; c++ {
-            w := getColWidth(c)
-            
-            text:=wrapText(data[r][c], w)
-            
-            // For last column don´t wrap
-            if c == numCols-1{
-                text=data[r][c]
-            }
-
-            cell := tview.NewTableCell(text)
-            cell.SetMaxWidth(w)
-            cell.SetExpansion(0)
-
-            // Only last column expands
-            if c == numCols-1 {
-                cell.SetExpansion(1)
-            }
-            
-            //Add the cell content to table
-            table.SetCell(r, c, cell)
-        }
+    // For last column don´t wrap
+    if c == numCols-1 {
+        text=cellText(0, c)
     }
+    cell := tview.NewTableCell(text)
+    styleHeaderCell(cell)
+    cell.SetSelectable(true)
+    cell.SetMaxWidth(w)
+    cell.SetExpansion(0)
+
+    // Only last column expands
+    if c == numCols-1 {
+        cell.SetExpansion(1)
+    }
+    return cell
+}
+
+func tableBodyCell(r int, c int) *tview.TableCell {
+    w := getColWidth(c)
+
+    text:=wrapText(cellText(r, c), w)
+
+    // For last column don´t wrap
+    if c == numCols-1{
+        text=cellText(r, c)
+    }
+
+    cell := tview.NewTableCell(text)
+    cell.SetMaxWidth(w)
+    cell.SetExpansion(0)
+
+    // Only last column expands
+    if c == numCols-1 {
+        cell.SetExpansion(1)
+    }
+    return cell
 }
 
+// Cells are produced on demand by tableContent ( see tablecontent.go ),
+// rendering only moves the selection and updates the status line
 func renderTable() {
-    table.Clear()
-    renderTableHeader()
-    renderTableBody()
-    table.Select(selectedRow, selectedCol)
+    // A row hidden by the filter can not be selected, move to the next visible one
+    row := viewRowOf(selectedRow)
+    selectedRow = dataRowOf(row)
+    table.Select(row, selectedCol)
+    updateStatusBar()
 }
 
 func wrapText(text string, width int) string {
@@ -294,108 +390,100 @@ func setupKeybindings() {
             // Ignore keys while editing, inputField handles
             return event
         }
+        clearMessage()
 
-        switch event.Key() {
-        case tcell.KeyRight:
-            if selectedCol < len(data[0])-1 {
-                selectedCol++
-                table.Select(selectedRow, selectedCol)
-            }
-            return nil
-        case tcell.KeyLeft:
-            if selectedCol > 0 {
-                selectedCol--
-                table.Select(selectedRow, selectedCol)
-            }
-            return nil
-        case tcell.KeyDown:
-            if selectedRow < len(data)-1 {
-                selectedRow++
-                table.Select(selectedRow, selectedCol)
-            }
-            return nil
-        case tcell.KeyUp:
-            if selectedRow==0{
-                table.Select(selectedRow, selectedCol)
-                return nil
-            }
-
-            if selectedRow > 0 {
-                selectedRow--
-                table.Select(selectedRow, selectedCol)
-            }
-            return nil
-        case tcell.KeyTab:
-            insertColumnRight()
-            refreshTable()
-            return nil
-        case tcell.KeyEnter:
-            insertRowBelow()
-            return nil
-        case tcell.KeyEscape:
-            confirmQuit()
-            //app.Stop()
-            return nil
-        case tcell.KeyBackspace2, tcell.KeyBackspace:
-            deleteColAfterConfirmation()
-            //deleteSelectedCol()
-            return nil
-        }
-
-        switch event.Rune() {
-        case 'd':
-            fCopyAndDelete:=func(){
-                copySelectedRowToCompleted()
-                deleteSelectedRow()
-            }
-            getUserConfirmation("Do you want to delete selected row?", fCopyAndDelete)
-            return nil
-        case 'e', 'i':
-            startEditing()
-            return nil
-        case 'c':
-            copyCellToClipboard()
-            return nil
-        case 'v':
-            pasteClipboardToCell()
-            return nil
-        case 'x':
-            cutCell()
-            return nil
-        case 'q':
-            confirmQuit()
-            //app.Stop()
-            return nil
-        case 'n': //n-null
-            clearCell()
+        // Keys are looked up in the keymap ( see keymap.go ), keys that are
+        // not bound go to the table
+        if handleKey(event) {
             return nil
         }
-
         return event
     })
 }
 
+// Move the cursor one cell, extend grows the range selection ( Shift + arrow )
+func moveCursor(rows int, cols int, extend bool) {
+    trackRangeSelection(extend)
+    if cols > 0 && selectedCol < numCols-1 {
+        selectedCol++
+    }
+    if cols < 0 && selectedCol > 0 {
+        selectedCol--
+    }
+
+    // Move in table rows, the filter may hide the data rows in between
+    row := viewRowOf(selectedRow)
+    if rows > 0 && row < tableRowCount()-1 {
+        row++
+        selectedRow = dataRowOf(row)
+    }
+    if rows < 0 && selectedRow != 0 && row > 0 {
+        row--
+        selectedRow = dataRowOf(row)
+    }
+    table.Select(row, selectedCol)
+}
+
+// Put the cursor on a table row and column ( gg, G, 0, $ in the vim keymap )
+func jumpCursor(row int, col int) {
+    trackRangeSelection(false)
+    if row > tableRowCount()-1 {
+        row = tableRowCount() - 1
+    }
+    if row < 0 {
+        row = 0
+    }
+    if col > numCols-1 {
+        col = numCols - 1
+    }
+    if col < 0 {
+        col = 0
+    }
+    selectedRow, selectedCol = dataRowOf(row), col
+    table.Select(row, col)
+}
+
 func clearCell() {
-    if selectedRow < 0 || selectedCol < 0 || selectedRow >= len(data) || selectedCol >= len(data[0]) {
+    if selectedRow < 0 || selectedCol < 0 || selectedRow >= len(data) || selectedCol >= numCols {
         return
     }
-    data[selectedRow][selectedCol] = ""
-    refreshTable()
+    setCell(selectedRow, selectedCol, "")
+}
+
+// Change a single cell through the undo history
+func setCell(row int, col int, text string) {
+    if headerReadOnly(row) {
+        return
+    }
+    old := cellText(row, col)
+    if old == text {
+        return
+    }
+    if runOp(&setCellOp{row: row, col: col, oldText: old, newText: text}, row, col) {
+        warnUnrepresentable(text)
+    }
 }
 
 func startEditing() {
+    if headerReadOnly(selectedRow) {
+        return
+    }
+    // Line breaks and long text go to the text area ( see editor.go )
+    if selectedRow < len(data) && needsTextEditor(cellText(selectedRow, selectedCol)) {
+        startTextEditing()
+        return
+    }
 	editing = true
     flexAddInputTextBox() 
-    inputField.SetText(data[selectedRow][selectedCol])
+    inputField.SetText(cellText(selectedRow, selectedCol))
 	//inputField.SetVisible(true)
 	app.SetFocus(inputField)
 }
 
 func onEditDone(key tcell.Key) {
 	if key == tcell.KeyEnter {
-		data[selectedRow][selectedCol] = inputField.GetText()
-		renderTable()
 		editing = false
+		setCell(selectedRow, selectedCol, inputField.GetText())
          // Clear the input field text
         inputField.SetText("")
 	    flexRemoveInputTextBox()
@@ -413,40 +501,13 @@ func onEditDone(key tcell.Key) {
 
 }
 
-func copyCellToClipboard() {
-	if selectedRow < len(data) && selectedCol < len(data[selectedRow]) {
-		text := data[selectedRow][selectedCol]
-		err := clipboard.WriteAll(text)
-		if err != nil {
-			log.Printf("Clipboard write failed: %v", err)
-		}
-	}
-}
-
-func pasteClipboardToCell() {
-	if selectedRow < len(data) && selectedCol < len(data[selectedRow]) {
-		text, err := clipboard.ReadAll()
-		if err == nil {
-			data[selectedRow][selectedCol] = text
-			renderTable()
-		}
-	}
-}
-
-func cutCell() {
-	if selectedRow < len(data) && selectedCol < len(data[selectedRow]) {
-		text := data[selectedRow][selectedCol]
-		err := clipboard.WriteAll(text)
-		if err == nil {
-			data[selectedRow][selectedCol] = ""
-			renderTable()
-		}
-	}
+func copySelectedRowToCompleted() {
+    row, _ := currentCell()
+    copyRowToCompleted(row)
 }
 
-func copySelectedRowToCompleted() {
-    row, _ := table.GetSelection()
-    
+// Append a row to <file>.completed.csv before it is deleted
+func copyRowToCompleted(row int) {
     if row == 0{
         //do not copy header row ( we are already it below when the completed csv file isn´t created)
         return
@@ -457,6 +518,10 @@ func copySelectedRowToCompleted() {
         return
     }
 
+    if !editingInPlace() {
+        // No file next to which it could go ( see pipe.go )
+        return
+    }
     completedFile := inputFile + ".completed.csv"
 
     // Check if file exists
@@ -471,10 +536,13 @@ func copySelectedRowToCompleted() {
     }
     defer f.Close()
 
-    writer := csv.NewWriter(f)
+    // Same format as the csv file, the BOM only at the start of a new file
+    d := doc.Dialect()
+    d.BOM = d.BOM && !fileExists
+    writer := csvio.NewWriter(f, d)
 
-    // If file does not exist, write header first
-    if !fileExists {
+    // If file does not exist, write header first ( labels are not written )
+    if !fileExists && doc.HasHeader() {
         if err := writer.Write(data[0]); err != nil {
             log.Printf("Error writing header to completed file: %v", err)
             return
@@ -499,42 +567,25 @@ func copySelectedRowToCompleted() {
 }
 
 func insertColumnRight() {
-	row, col := table.GetSelection()
+	row, col := currentCell()
 
 	// Sanity checks
-	if len(data) == 0 || row < 0 || col < 0 || row >= len(data) || col >= len(data[0]) {
+	if len(data) == 0 || row < 0 || col < 0 || row >= len(data) || col >= numCols {
+		return
+	}
+	if loadBusy() {
 		return
 	}
 
 	// Determine where to insert: after current column
 	insertAt := col + 1
 
-	// Insert empty string into each row at insertAt position
-	for i := range data {
-		if insertAt >= len(data[i]) {
-			// Append if insertAt is at the end
-			data[i] = append(data[i], "")
-		} else {
-			// Insert in the middle
-			data[i] = append(data[i][:insertAt+1], data[i][insertAt:]...)
-			data[i][insertAt] = ""
-		}
-	}
-
-	// Update selection to the new column
-	selectedRow = row
-	selectedCol = insertAt
-    numCols+=1
-	//saveCSV(inputFile)
-
-
-	// Re-render table
-	renderTable()
-	app.SetFocus(table)
+	// Insert empty string into each row at insertAt position and select the new column
+	runOp(&insertColOp{at: insertAt, cells: make([]string, len(data))}, row, insertAt)
 }
 
 func insertRowBelow() {
-    row, _ := table.GetSelection()
+    row, _ := currentCell()
 
     // Prevent inserting before header row (row 0 is usually the header)
     if row < 0 || row >= len(data) {
@@ -545,27 +596,12 @@ func insertRowBelow() {
     newRow := make([]string, len(data[0]))
 
     // Insert the new row below the selected one
-    if row+1 >= len(data) {
-        data = append(data, newRow)
-        selectedRow = len(data) - 1
-    } else {
-        data = append(data[:row+1], append([][]string{newRow}, data[row+1:]...)...)
-        selectedRow = row + 1
-    }
-
-    selectedCol = 0
-    numRows+=1
-
-    //saveCSV(inputFile)
-
-    // Re-render table
-    renderTable()
-    app.SetFocus(table)
+    runOp(&insertRowOp{at: row + 1, cells: newRow}, row+1, 0)
 }
 
 
 func deleteSelectedRow() {
-    row, _ := table.GetSelection()
+    row, _ := currentCell()
 
     if row == 0 {
         //do allow to delete header row
@@ -577,55 +613,40 @@ func deleteSelectedRow() {
         return
     }
 
-    // Remove the row
-    data = append(data[:row], data[row+1:]...)
-
     // Adjust selection
-    if row >= len(data) {
-        selectedRow = len(data) - 1
-    } else {
-        selectedRow = row
+    newRow := row
+    if row >= len(data)-1 {
+        newRow = len(data) - 2
     }
-    selectedCol = 0
-    numRows -= 1
-    //saveCSV(inputFile)
-    
-    // Re-render table
-    renderTable()
-    app.SetFocus(table)
+
+    // Remove the row
+    runOp(&deleteRowOp{at: row}, newRow, 0)
 }
 
 func deleteSelectedCol() {
-	row, col := table.GetSelection()
+	row, col := currentCell()
 
 	// Sanity checks
-	if len(data) == 0 || row < 0 || col < 0 || col >= len(data[0]) {
+	if len(data) == 0 || row < 0 || col < 0 || col >= numCols {
 		return
 	}
 
 	// Avoid deleting if only one column left
-	if len(data[0]) <= 1 {
+	if numCols <= 1 {
 		return
 	}
-
-	// Remove the column at index col in every row
-	for i := range data {
-		data[i] = append(data[i][:col], data[i][col+1:]...)
+	if loadBusy() {
+		return
 	}
 
 	// Adjust selected column if needed
-	if selectedCol >= len(data[0]) {
-		selectedCol = len(data[0]) - 1
+	newCol := col
+	if newCol >= numCols-1 {
+		newCol = numCols - 2
 	}
-	selectedRow = row
-
-	numCols -= 1
 
-	//saveCSV(inputFile)
-
-	// Re-render table
-	renderTable()
-	app.SetFocus(table)
+	// Remove the column at index col in every row
+	runOp(&deleteColOp{at: col}, row, newCol)
 }
 
 func getConfigPath(csvPath string) string {
@@ -635,20 +656,20 @@ func getConfigPath(csvPath string) string {
 }
 
 func forceWriteConfig(path string) {
-    log.Println("Config file corrupted. Overwriting with current data...")
+    showMessage("Config file corrupted. Overwriting with current data...")
     err := os.Remove(path)
     if err != nil {
-        log.Printf("Error removing bad config file: %v\n", err)
+        showMessage("Error removing bad config file: %v", err)
         return
     }
     createConfig(path)
 }
 
 func createConfig(path string) {
-    fmt.Println("Creating new config file...")
+    showMessage("Creating new config file...")
     file, err := os.Create(path)
     if err != nil {
-        fmt.Printf("Error creating config file: %v\n", err)
+        showMessage("Error creating config file: %v", err)
         return
     }
     defer file.Close()
@@ -660,13 +681,42 @@ func createConfig(path string) {
     writer.Flush()
 }
 
+// Write the current column widths to a config file
+func writeConfig(path string) {
+    if len(colWidths) == 0 {
+        createConfig(path)
+        return
+    }
+
+    file, err := os.Create(path)
+    if err != nil {
+        showMessage("Error creating config file: %v", err)
+        return
+    }
+    defer file.Close()
+
+    cols := make([]int, 0, len(colWidths))
+    for col := range colWidths {
+        cols = append(cols, col)
+    }
+    sort.Ints(cols)
+
+    writer := bufio.NewWriter(file)
+    for _, col := range cols {
+        fmt.Fprintf(writer, "%d:%d\n", col, colWidths[col])
+    }
+    writer.Flush()
+}
+
 func loadCSVConfig() {
+    if readingStdin() {
+        return
+    }
     path := getConfigPath(inputFile)
      
     widths := make(map[int]int)
     file, err := os.Open(path)
     if err != nil {
-        log.Printf("No config file found or error loading it, using default widths\n")
         createConfig(path)
         return
     }
@@ -678,7 +728,7 @@ func loadCSVConfig() {
         if line == "" || strings.HasPrefix(line, "#") {
             continue
         }
-        parts := strings.Split(line, ":")
+        parts := strings.SplitN(line, ":", 2)
         if len(parts) != 2 {
             continue
         }
@@ -686,17 +736,21 @@ func loadCSVConfig() {
         w, err2 := strconv.Atoi(parts[1])
         if err1 == nil && err2 == nil {
             widths[colNum] = w
+            continue
+        }
+        // Named options ( e.g. undo_depth:200 ), command line flags take precedence
+        if parts[0] == "undo_depth" && err2 == nil && !flagIsSet("undo-depth") {
+            undoDepth = w
         }
     }
 
     err = scanner.Err() 
     
     if err != nil {
-        log.Printf("Config parsing error\n")
+        showMessage("Config parsing error")
         forceWriteConfig(path)
         return
     }else{
-        log.Printf("Loaded config [%s]\n", path)
         colWidths=widths
     }
 }
@@ -706,76 +760,92 @@ func refreshTable(){
     app.SetFocus(table)
 }
 
-func saveCSV(filename string) {
-    tempFile := filename + ".tmp"
+// Write data to filename through a temp file, the file is either fully written or untouched.
+// Returns a warning when cells had characters the encoding can not store.
+func writeCSVFile(filename string) (string, error) {
+    if loadBusy() {
+        return "", fmt.Errorf("file is still loading")
+    }
+    if loadErr != nil {
+        return "", fmt.Errorf("file was not loaded completely, saving is disabled")
+    }
 
-    f, err := os.Create(tempFile)
-    if err != nil {
-        log.Printf("Error creating temp CSV: %v", err)
-        return
+    // Unchanged rows are written as they were read ( see rowformat.go )
+    if err := doc.WriteFile(filename); err != nil {
+        return "", err
     }
-    defer f.Close()
 
-    w := csv.NewWriter(f)
-    err = w.WriteAll(data)
-    if err != nil {
-        log.Printf("Error writing CSV data: %v", err)
-        return
+    warning := ""
+    if n, row, col := doc.Lost(); n > 0 {
+        warning = fmt.Sprintf(", %d cell(s) had characters %s can not store, saved as ? ( first: row %d col %d )", n, doc.Dialect().Encoding, row, col+1)
     }
-    w.Flush()
+    return warning, nil
+}
 
-    err = os.Rename(tempFile, filename)
-    if err != nil {
-        log.Printf("Error renaming temp file: %v", err)
-        return
+// Save data to filename, returns false if nothing was saved
+func saveCSV(filename string) bool {
+    // See pipe.go
+    if filename == inputFile && stdoutFlag {
+        return saveStdoutResult()
+    }
+    if filename == stdinPath {
+        saveAs()
+        return false
     }
 
-    // Re-read the saved file into `data`
-    file, err := os.Open(filename)
+    warning, err := writeCSVFile(filename)
     if err != nil {
-        log.Printf("Error reopening saved CSV: %v", err)
-        return
+        showMessage("Error: %v", err)
+        return false
     }
-    defer file.Close()
 
-    //Reloading CSV
-    loadCSV()
-    renderTable()
-    app.SetFocus(table)
+    // Everything is on disk now, the journal is not needed anymore
+    journalDiscard()
+    markSaved()
+    saveHeaderChoice(filename)
+    showMessage("Saved %s%s", filename, warning)
+    return true
 }
 
-func magnify_cell_full_screen(app *tview.Application, content string, table *tview.Table, pages *tview.Pages) {
-	textView := tview.NewTextView()
-	textView.SetText(content).
-		SetTextColor(tcell.ColorWhite).
-		SetBackgroundColor(tcell.ColorBlack).
-		SetBorder(true).
-		SetTitle("Cell Content Full Screen (Press Esc to close)")
-
-	textView.ScrollToBeginning()
-
-	textView.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-		if event.Key() == tcell.KeyEscape {
-			pages.RemovePage("magnify")
-			app.SetFocus(table)
-			return nil
-		}
-		return event
-	})
-
-	modal := tview.NewFlex().
-		SetDirection(tview.FlexRow).
-		AddItem(nil, 1, 0, false). // top padding: 1 row
-		AddItem(
-			tview.NewFlex().
-				AddItem(nil, 2, 0, false).      // left padding: 2 cols
-				AddItem(textView, 0, 1, true).  // main content
-				AddItem(nil, 2, 0, false),      // right padding: 2 cols
-			0, 1, true).
-		AddItem(nil, 1, 0, false) // bottom padding: 1 row
-
-	pages.AddPage("magnify", modal, true, true)
-	app.SetFocus(textView)
+// Save to a new file and keep editing that file
+func saveAsFile(path string) bool {
+    if !saveCSV(path) {
+        return false
+    }
+    if path == inputFile {
+        return true
+    }
+    inputFile = path
+
+    // Take the column widths along to the new file
+    configPath := getConfigPath(inputFile)
+    if _, err := os.Stat(configPath); os.IsNotExist(err) {
+        writeConfig(configPath)
+    }
+    updateStatusBar()
+    return true
+}
+
+func saveAs() {
+    name := inputFile
+    if readingStdin() {
+        name = ""
+    }
+    promptInput("Save as: ", name, func(path string) {
+        if path == "" {
+            return
+        }
+
+        write := func() {
+            saveAsFile(path)
+        }
+
+        if _, err := os.Stat(path); err == nil && path != inputFile {
+            getUserConfirmation(fmt.Sprintf("%s already exists. Overwrite it?", path), write)
+            return
+        }
+        write()
+    })
 }
 
 func simulateRightArrowKeyPressEvent(){
@@ -837,10 +907,22 @@ func deleteColAfterConfirmation() {
 
 
 
+// Quit right away when everything is saved, otherwise ask what to do with the changes
+// With several files open only the current tab is closed
 func confirmQuit() {
+	if !doc.Dirty() {
+		closeCurrentTab()
+		return
+	}
+
+	save, discard := "Save & Quit", "Discard & Quit"
+	if len(tabs) > 1 {
+		save, discard = "Save & Close", "Discard & Close"
+	}
+
 	modal := tview.NewModal().
-		SetText("Do you want to close the application?").
-		AddButtons([]string{"Yes", "No"}).
+		SetText("There are unsaved changes. Save them before closing?").
+		AddButtons([]string{save, discard, "Cancel"}).
 		SetButtonBackgroundColor(tcell.ColorDarkCyan).
 		SetButtonStyle(tcell.StyleDefault.
 			Foreground(tcell.ColorWhite).
@@ -850,17 +932,22 @@ func confirmQuit() {
 			Background(tcell.ColorDarkCyan).
 			Bold(true)).
 		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
-			if buttonLabel == "Yes" {
-	            saveCSV(inputFile)
-				app.Stop()
-			} else {
-				pages.RemovePage("confirm")
+			pages.RemovePage("confirm")
+			switch buttonLabel {
+			case save:
+				if saveCSV(inputFile) {
+					closeCurrentTab()
+				}
+			case discard:
+				journalDiscard()
+				closeCurrentTab()
 			}
 		})
 
 	pages.AddPage("confirm", modal, true, true)
 
-    // Optional: preselect "No"
+    // Optional: preselect "Cancel"
+    simulateRightArrowKeyPressEvent()
     simulateRightArrowKeyPressEvent()
 }
 
@@ -874,18 +961,51 @@ func refresh(){
 
 func main() {
     argParse()
-    loadCSV()
+    err := appInit()
+    if err != nil {
+        fmt.Printf("Error: reading csv file: %v\n", err)
+        os.Exit(1)
+    }
+    uiLoop(pages)
+    os.Exit(writeStdoutResult())
+}
+
+// Read the file and build the UI, the UI tests ( ui_test.go ) start here as well
+func appInit() error {
+    err := loadCSV(inputFile)
+    if err != nil {
+        return err
+    }
     loadCSVConfig()
     uiInit()
     pageInit()
     tableInit()
     inputTextBoxInit()
+    statusBarInit()
+    commandLineInit()
+    searchInit()
+    registerReplaceCommands()
+    registerSortCommands()
+    filterInit()
+    registerDialectOptions()
+    registerMalformedCommands()
+    registerHeaderCommands()
+    registerTabCommands()
+    registerActions()
+    registerKeymapCommands()
+    registerHelpCommands()
+    registerEditorOptions()
+    registerFormCommands()
+    loadKeymap()
     renderTable()
+    tabBarInit()
     flexInit()
     flexAddTable()
-    createNewPage(flex)
+    createNewPage("1", flex)
+    tabsInit()
     setupKeybindings()
-    uiLoop(pages)
+    startLoader()
+    return nil
 }
 
 
@@ -0,0 +1,307 @@
+/**
+ * MIT License
+ *
+ * Copyright (c) 2025 Viki (VN - initials of my first and last name)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ *
+ * Contact: contact@viki.design
+ * Website: https://www.viki.design
+ *
+ */
+
+
+package main
+
+/*
+  Dialect:
+
+    Delimiter, quote character and header row of the file. They are guessed
+    from the first 64 KB ( csvio.Sniff ) and can be set in the config file or,
+    for every file given on the command line, with flags ( flags win ):
+
+        delimiter:;      -delimiter ';'     ( a character or tab, comma, semicolon, pipe, colon, space )
+        quote:'          -quote "'"
+        header:no        -no-header         ( yes / no, see header.go )
+        encoding:latin-1 -encoding latin-1  ( utf-8, utf-16le, utf-16be, latin-1, windows-1252 )
+
+    Line endings, BOM and quoting are detected as well ( see rowformat.go ).
+    The encoding is guessed from the raw bytes before anything else ( BOM,
+    zero bytes of UTF-16, valid UTF-8, else Windows-1252 / Latin-1 ); the
+    rows are kept as UTF-8 and converted back on save. Characters the
+    encoding can not store are reported when they are typed and on save.
+    The file is written back in the dialect it was read in; :set delimiter=...
+    and :set quote=... change the dialect used for the next save.
+*/
+
+import (
+    "bufio"
+    "flag"
+    "fmt"
+    "os"
+    "slices"
+    "strings"
+
+    "neoviki_spreadsheet/modules_neoviki/csvio"
+)
+
+var (
+    delimiterFlag string
+    quoteFlag     string
+    encodingFlag  string
+)
+
+func dialectFlags() {
+    flag.StringVar(&delimiterFlag, "delimiter", "", "field delimiter: a character or tab, comma, semicolon, pipe ( default: detected )")
+    flag.StringVar(&quoteFlag, "quote", "", "quote character ( default: detected )")
+    flag.StringVar(&encodingFlag, "encoding", "", "utf-8, utf-16le, utf-16be, latin-1 or windows-1252 ( default: detected )")
+    flag.BoolVar(&noHeaderFlag, "no-header", false, "the first row is data, label the columns A, B, C ... ( default: detected )")
+}
+
+// Dialect keys ( and labels ) of the config file of csvPath
+func readDialectConfig(csvPath string) map[string]string {
+    values := make(map[string]string)
+    if csvPath == stdinPath {
+        return values
+    }
+    file, err := os.Open(getConfigPath(csvPath))
+    if err != nil {
+        return values
+    }
+    defer file.Close()
+
+    scanner := bufio.NewScanner(file)
+    for scanner.Scan() {
+        // No TrimSpace, "delimiter: " is a space
+        parts := strings.SplitN(strings.TrimRight(scanner.Text(), "\r"), ":", 2)
+        if len(parts) != 2 {
+            continue
+        }
+        switch parts[0] {
+        case "delimiter", "quote", "header", "encoding", "labels":
+            values[parts[0]] = parts[1]
+        }
+    }
+    return values
+}
+
+// Set key in the config file of csvPath, the other lines are kept
+func setConfigValue(csvPath string, key string, value string) error {
+    if csvPath == stdinPath || stdoutFlag {
+        // The choice is for this session only ( see pipe.go )
+        return nil
+    }
+    path := getConfigPath(csvPath)
+    content, err := os.ReadFile(path)
+    if err != nil && !os.IsNotExist(err) {
+        return err
+    }
+
+    var lines []string
+    found := false
+    for _, line := range strings.Split(strings.TrimRight(string(content), "\n"), "\n") {
+        if line == "" {
+            continue
+        }
+        if strings.HasPrefix(line, key+":") {
+            if found {
+                continue
+            }
+            line = key + ":" + value
+            found = true
+        }
+        lines = append(lines, line)
+    }
+    if !found {
+        lines = append(lines, key+":"+value)
+    }
+    return os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0644)
+}
+
+func parseHeaderValue(value string) (bool, error) {
+    switch strings.ToLower(strings.TrimSpace(value)) {
+    case "yes", "true", "1", "on":
+        return true, nil
+    case "no", "false", "0", "off":
+        return false, nil
+    }
+    return false, fmt.Errorf("bad header value %q ( yes / no )", value)
+}
+
+// Encoding of path from the raw start of the file, the config file and the flag
+func detectEncoding(path string, rawSample []byte) (string, error) {
+    enc := csvio.DetectEncoding(rawSample)
+
+    if v, ok := readDialectConfig(path)["encoding"]; ok {
+        if e, err := csvio.ParseEncoding(v); err == nil {
+            enc = e
+        } else {
+            showMessage("Config: %v", err)
+        }
+    }
+    if onCommandLine(path) && encodingFlag != "" {
+        e, err := csvio.ParseEncoding(encodingFlag)
+        if err != nil {
+            return enc, err
+        }
+        enc = e
+    }
+    return enc, nil
+}
+
+// The flags are meant for the files on the command line ( every tab ), not
+// for :e
+func onCommandLine(path string) bool {
+    return slices.Contains(inputFiles, path)
+}
+
+// Sniffed dialect of path, with the config file and the flags applied.
+// sample is already decoded to UTF-8.
+func detectDialect(path string, sample []byte) (csvio.Dialect, error) {
+    d := csvio.Sniff(sample)
+
+    cfg := readDialectConfig(path)
+    if v, ok := cfg["delimiter"]; ok {
+        if r, err := csvio.ParseDelimiter(v); err == nil {
+            d.Delimiter = r
+        } else {
+            showMessage("Config: %v", err)
+        }
+    }
+    if v, ok := cfg["quote"]; ok {
+        if r, err := csvio.ParseQuote(v); err == nil {
+            d.Quote = r
+        } else {
+            showMessage("Config: %v", err)
+        }
+    }
+    if v, ok := cfg["header"]; ok {
+        if h, err := parseHeaderValue(v); err == nil {
+            d.HasHeader = h
+        } else {
+            showMessage("Config: %v", err)
+        }
+    }
+
+    if v, ok := cfg["labels"]; ok && (v == "letters" || v == "numbers") {
+        labelStyle = v
+    }
+
+    if onCommandLine(path) {
+        if noHeaderFlag {
+            d.HasHeader = false
+        }
+        if delimiterFlag != "" {
+            r, err := csvio.ParseDelimiter(delimiterFlag)
+            if err != nil {
+                return d, err
+            }
+            d.Delimiter = r
+        }
+        if quoteFlag != "" {
+            r, err := csvio.ParseQuote(quoteFlag)
+            if err != nil {
+                return d, err
+            }
+            d.Quote = r
+        }
+    }
+
+    return d, d.Validate()
+}
+
+func registerDialectOptions() {
+    registerOption(&option{
+        name: "delimiter",
+        help: "Field delimiter used when saving",
+        get:  func() string { return csvio.DelimiterName(doc.Dialect().Delimiter) },
+        set: func(value string) error {
+            r, err := csvio.ParseDelimiter(value)
+            if err != nil {
+                return err
+            }
+            d := doc.Dialect()
+            d.Delimiter = r
+            return setDialect(d)
+        },
+    })
+    registerOption(&option{
+        name: "quote",
+        help: "Quote character used when saving",
+        get:  func() string { return string(doc.Dialect().Quote) },
+        set: func(value string) error {
+            r, err := csvio.ParseQuote(value)
+            if err != nil {
+                return err
+            }
+            d := doc.Dialect()
+            d.Quote = r
+            return setDialect(d)
+        },
+    })
+
+    registerOption(&option{
+        name: "encoding",
+        help: "Character encoding used when saving",
+        get:  func() string { return doc.Dialect().Encoding },
+        set: func(value string) error {
+            e, err := csvio.ParseEncoding(value)
+            if err != nil {
+                return err
+            }
+            d := doc.Dialect()
+            d.Encoding = e
+            return setDialect(d)
+        },
+    })
+
+    registerCommand(&command{
+        name: "dialect",
+        help: "Show the delimiter, quote character and header setting of the file",
+        run: func(bang bool, args string) error {
+            showMessage("%s", doc.Dialect())
+            return nil
+        },
+    })
+}
+
+// Tell right away when a cell gets characters the file encoding can not store
+func warnUnrepresentable(text string) {
+    if w := unrepresentableWarning(text); w != "" {
+        showMessage("Warning: %s", w)
+    }
+}
+
+func unrepresentableWarning(text string) string {
+    if csvio.Representable(doc.Dialect().Encoding, text) {
+        return ""
+    }
+    return fmt.Sprintf("%s can not store some of these characters, they will be saved as ? ( :set encoding=utf-8 )", doc.Dialect().Encoding)
+}
+
+// The file is saved differently from now on, so it counts as a change
+func setDialect(d csvio.Dialect) error {
+    if err := doc.SetDialect(d); err != nil {
+        return err
+    }
+    updateStatusBar()
+    return nil
+}
@@ -0,0 +1,81 @@
+/**
+ * MIT License
+ *
+ * Copyright (c) 2025 Viki (VN - initials of my first and last name)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ *
+ * Contact: contact@viki.design
+ * Website: https://www.viki.design
+ *
+ */
+
+package main
+
+/*
+  Document:
+
+    The rows of the file, its dialect and whether it has unsaved changes
+    live in a document.Document ( modules_neoviki/document ), the editor
+    changes it only through its methods. `data`, `rowFormats`, `numRows` and
+    `numCols` are kept in step with the document after every change, the
+    table, search, filter ... read them directly.
+*/
+
+import (
+    "neoviki_spreadsheet/modules_neoviki/csvio"
+    "neoviki_spreadsheet/modules_neoviki/document"
+)
+
+var doc *document.Document
+
+// The file that was read last becomes the current document
+func openDocument(d csvio.Dialect, header []string, format *csvio.RowFormat) {
+    doc = document.New(d, header, format)
+    doc.Label = columnLabel
+    doc.Relabel()
+    doc.OnChange(documentChanged)
+    syncDocument()
+}
+
+func syncDocument() {
+    data = doc.Rows()
+    rowFormats = doc.Formats()
+    numRows = doc.NumRows()
+    numCols = doc.NumCols()
+}
+
+func documentChanged(c document.Change) {
+    syncDocument()
+    switch c.Kind {
+    case document.RowInserted:
+        filterRowInserted(c.Row)
+    case document.RowDeleted:
+        filterRowDeleted(c.Row)
+    case document.RowsReordered:
+        filterRowsReordered(c.Row, c.Order)
+    case document.HeaderChanged:
+        headerChanged()
+    case document.DialectChanged:
+        // Not in the undo history, undo can not get back to the saved file
+        savedDepth = -1
+    }
+}
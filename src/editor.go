@@ -0,0 +1,166 @@
+/**
+ * MIT License
+ *
+ * Copyright (c) 2025 Viki (VN - initials of my first and last name)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ *
+ * Contact: contact@viki.design
+ * Website: https://www.viki.design
+ *
+ */
+
+package main
+
+/*
+  Cell editor:
+
+    Cells with line breaks ( allowed in quoted csv fields ) or longer than
+    :set longcell ( 80 characters ) are edited in a popup text area instead
+    of the input line below the table, E opens it for any cell. The text is
+    selected when the editor opens, so typing replaces it.
+
+        Ctrl+Enter   save the cell ( Ctrl+J, Alt+Enter and Ctrl+S as well,
+                     most terminals send Ctrl+Enter as one of them or as Enter )
+        Enter        new line
+        Ctrl+Z / Y   undo / redo inside the editor
+        Esc          cancel ( twice when the text was changed )
+
+    Saving the cell is one change in the undo history of the table.
+*/
+
+import (
+    "fmt"
+    "strconv"
+    "strings"
+    "unicode/utf8"
+
+    "github.com/gdamore/tcell/v2"
+    "github.com/rivo/tview"
+)
+
+const defaultLongCell = 80
+
+// Cells longer than this open in the text area editor
+var longCell = defaultLongCell
+
+// Edit in the text area when the cell has line breaks or is long
+func needsTextEditor(text string) bool {
+    return strings.ContainsAny(text, "\r\n") || utf8.RuneCountInString(text) > longCell
+}
+
+// E: edit the cell in the text area editor
+func startTextEditing() {
+    if headerReadOnly(selectedRow) || selectedRow >= len(data) || selectedCol >= numCols {
+        return
+    }
+    row, col := selectedRow, selectedCol
+
+    editing = true
+    closeEditor := func() {
+        editing = false
+        pages.RemovePage("editor")
+        app.SetFocus(table)
+    }
+    title := fmt.Sprintf(" Row %d, %s ( Ctrl+Enter: save, Esc: cancel ) ", row, tview.Escape(strings.TrimSpace(wrapText(cellText(0, col), 20))))
+    editor := newCellTextArea(cellText(row, col), title, func(text string) {
+        closeEditor()
+        setCell(row, col, text)
+    }, closeEditor)
+
+    popup := tview.NewFlex().
+        SetDirection(tview.FlexRow).
+        AddItem(nil, 3, 0, false).
+        AddItem(
+            tview.NewFlex().
+                AddItem(nil, 6, 0, false).
+                AddItem(editor, 0, 1, true).
+                AddItem(nil, 6, 0, false),
+            0, 1, true).
+        AddItem(nil, 6, 0, false)
+    pages.AddPage("editor", popup, true, true)
+    app.SetFocus(editor)
+}
+
+// Text area with text selected ( typing replaces it ), save is called on
+// Ctrl+Enter, cancel on Esc ( twice when the text was changed ). Also used by
+// the cell magnifier ( magnify.go ).
+func newCellTextArea(text string, title string, save func(string), cancel func()) *tview.TextArea {
+    editor := tview.NewTextArea().SetWrap(true).SetWordWrap(true)
+    editor.SetText(text, false)
+    editor.SetClipboard(func(text string) { clipboardWrite(text) }, func() string {
+        text, _ := clipboardRead()
+        return text
+    })
+    editor.SetBorder(true)
+    editor.SetTitle(title)
+
+    cancelArmed := false
+    editor.SetChangedFunc(func() {
+        if cancelArmed {
+            cancelArmed = false
+            editor.SetTitle(title)
+        }
+    })
+    editor.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+        switch event.Key() {
+        case tcell.KeyCtrlJ, tcell.KeyCtrlS:
+            save(editor.GetText())
+            return nil
+        case tcell.KeyEnter:
+            if event.Modifiers()&(tcell.ModCtrl|tcell.ModAlt) != 0 {
+                save(editor.GetText())
+                return nil
+            }
+        case tcell.KeyEscape:
+            if editor.GetText() != text && !cancelArmed {
+                cancelArmed = true
+                editor.SetTitle(" Changes not saved: Esc again discards them, Ctrl+Enter saves ")
+                return nil
+            }
+            cancel()
+            return nil
+        }
+        return event
+    })
+
+    // Select needs the width of the text area, known after it is drawn
+    go app.QueueUpdateDraw(func() {
+        editor.Select(0, editor.GetTextLength())
+    })
+    return editor
+}
+
+func registerEditorOptions() {
+    registerOption(&option{
+        name: "longcell",
+        help: "Cells longer than this open in the text area editor",
+        get:  func() string { return strconv.Itoa(longCell) },
+        set: func(value string) error {
+            n, err := strconv.Atoi(value)
+            if err != nil || n < 1 {
+                return fmt.Errorf("longcell needs a number > 0")
+            }
+            longCell = n
+            return nil
+        },
+    })
+}
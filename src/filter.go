@@ -0,0 +1,693 @@
+/**
+ * MIT License
+ *
+ * Copyright (c) 2025 Viki (VN - initials of my first and last name)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ *
+ * Contact: contact@viki.design
+ * Website: https://www.viki.design
+ *
+ */
+
+
+package main
+
+/*
+  Filter:
+
+    `f` ( or :filter <expression> ) hides the rows that do not match an
+    expression. The rows stay in `data`, only the table shows fewer of them:
+    viewRows maps table rows to data rows ( table row 0 is always the header ).
+    selectedRow and every op keep working with data rows, so edits in the
+    filtered view go to the right rows.
+
+    Expressions:
+
+        Status != "done" && Priority >= 2
+        Status == open
+        Name contains "pot" or `Due date` < 2025-11-01
+        not (Owner startswith "a") || $3 =~ "^[0-9]+$"
+
+    Columns are referenced by header name ( case insensitive, `...` for names
+    with spaces or other characters ) or by number ( $1, $2, ... ). Values are
+    compared as numbers if both sides are numbers, as dates if both are dates
+    and as case insensitive text otherwise.
+
+    Text is quoted with "..." or '...'. A backslash before the quote puts it
+    in the text ( "say \"hi\"" ), every other backslash is kept as it is, so
+    patterns need no doubled backslashes ( email =~ "\.com$" ).
+
+        == = != <>  < <= > >=       compare
+        contains startswith endswith ( case insensitive )
+        =~ !~                       regular expression match
+        && and  || or  ! not  ( )
+        a column on its own         true if the cell is not empty
+
+    Rows that are inserted while a filter is active are shown, and a row that
+    stops matching after an edit stays visible until the filter is applied
+    again ( :filter without an expression ).
+*/
+
+import (
+    "fmt"
+    "regexp"
+    "sort"
+    "strconv"
+    "strings"
+    "unicode"
+
+    "github.com/gdamore/tcell/v2"
+    "github.com/rivo/tview"
+)
+
+var (
+    filterActive bool
+    filterText   string
+
+    // Table row -> data row, only used while filterActive ( sorted )
+    viewRows []int
+
+    filterField *tview.InputField
+
+    // Filter when the prompt was opened ( Esc goes back to it )
+    filterPrevText string
+)
+
+// Row mapping
+
+func tableRowCount() int {
+    if !filterActive {
+        return len(data)
+    }
+    return len(viewRows)
+}
+
+func dataRowOf(viewRow int) int {
+    if !filterActive || viewRow < 0 {
+        return viewRow
+    }
+    if viewRow >= len(viewRows) {
+        return len(data)
+    }
+    return viewRows[viewRow]
+}
+
+// Table row of a data row, the next visible row if it is hidden
+func viewRowOf(dataRow int) int {
+    if !filterActive {
+        return dataRow
+    }
+    i := sort.SearchInts(viewRows, dataRow)
+    if i >= len(viewRows) {
+        i = len(viewRows) - 1
+    }
+    return i
+}
+
+func rowVisible(dataRow int) bool {
+    if !filterActive {
+        return true
+    }
+    i := sort.SearchInts(viewRows, dataRow)
+    return i < len(viewRows) && viewRows[i] == dataRow
+}
+
+// Cursor in data rows ( used instead of table.GetSelection() )
+func currentCell() (int, int) {
+    row, col := table.GetSelection()
+    return dataRowOf(row), col
+}
+
+// Keep viewRows in step with the data helpers in history.go
+
+func filterRowInserted(at int) {
+    if !filterActive {
+        return
+    }
+    i := sort.SearchInts(viewRows, at)
+    for j := i; j < len(viewRows); j++ {
+        viewRows[j]++
+    }
+    viewRows = append(viewRows, 0)
+    copy(viewRows[i+1:], viewRows[i:])
+    viewRows[i] = at
+}
+
+func filterRowDeleted(at int) {
+    if !filterActive {
+        return
+    }
+    i := sort.SearchInts(viewRows, at)
+    if i < len(viewRows) && viewRows[i] == at {
+        viewRows = append(viewRows[:i], viewRows[i+1:]...)
+    }
+    for j := i; j < len(viewRows); j++ {
+        viewRows[j]--
+    }
+}
+
+func filterRowsReordered(from int, order []int) {
+    if !filterActive {
+        return
+    }
+    lo := sort.SearchInts(viewRows, from)
+    hi := sort.SearchInts(viewRows, from+len(order))
+    inv := invertOrder(order)
+    for j := lo; j < hi; j++ {
+        viewRows[j] = from + inv[viewRows[j]-from]
+    }
+    sort.Ints(viewRows[lo:hi])
+}
+
+// Expression parser
+
+const (
+    filterTokEOF = iota
+    filterTokString
+    filterTokNumber
+    filterTokColumn
+    filterTokOp
+)
+
+type filterToken struct {
+    kind int
+    text string
+    pos  int
+}
+
+// Operators and keywords, longest first
+var filterOps = []string{"&&", "||", "==", "!=", "<>", "<=", ">=", "=~", "!~", "=", "<", ">", "!", "(", ")"}
+var filterWords = []string{"and", "or", "not", "contains", "startswith", "endswith"}
+
+func isFilterIdentRune(r rune, first bool) bool {
+    if r == '_' || unicode.IsLetter(r) {
+        return true
+    }
+    return !first && (unicode.IsDigit(r) || r == '.')
+}
+
+func tokenizeFilter(text string) ([]filterToken, error) {
+    var tokens []filterToken
+    runes := []rune(text)
+
+    for i := 0; i < len(runes); {
+        r := runes[i]
+        start := i
+        switch {
+        case unicode.IsSpace(r):
+            i++
+            continue
+
+        case r == '"' || r == '\'' || r == '`':
+            // String, or `column name`
+            var sb strings.Builder
+            i++
+            for i < len(runes) && runes[i] != r {
+                // \" is the quote, other backslashes stay for patterns ( "\.com$" )
+                if runes[i] == '\\' && i+1 < len(runes) && runes[i+1] == r {
+                    i++
+                }
+                sb.WriteRune(runes[i])
+                i++
+            }
+            if i >= len(runes) {
+                return nil, fmt.Errorf("missing closing %c at position %d", r, start+1)
+            }
+            i++
+            kind := filterTokString
+            if r == '`' {
+                kind = filterTokColumn
+            }
+            tokens = append(tokens, filterToken{kind, sb.String(), start})
+            continue
+
+        case r == '$' && i+1 < len(runes) && unicode.IsDigit(runes[i+1]):
+            i++
+            for i < len(runes) && unicode.IsDigit(runes[i]) {
+                i++
+            }
+            tokens = append(tokens, filterToken{filterTokColumn, string(runes[start:i]), start})
+            continue
+
+        case unicode.IsDigit(r) || (r == '-' || r == '.') && i+1 < len(runes) && unicode.IsDigit(runes[i+1]):
+            // Numbers and dates ( 2025-10-01, 01.10.2025, 10/01/2025 ) are literals
+            i++
+            for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || strings.ContainsRune(".-/:", runes[i])) {
+                i++
+            }
+            tokens = append(tokens, filterToken{filterTokNumber, string(runes[start:i]), start})
+            continue
+
+        case isFilterIdentRune(r, true):
+            for i < len(runes) && isFilterIdentRune(runes[i], i == start) {
+                i++
+            }
+            word := string(runes[start:i])
+            kind := filterTokColumn
+            for _, w := range filterWords {
+                if strings.EqualFold(word, w) {
+                    kind = filterTokOp
+                    word = w
+                }
+            }
+            tokens = append(tokens, filterToken{kind, word, start})
+            continue
+        }
+
+        found := false
+        for _, op := range filterOps {
+            if strings.HasPrefix(string(runes[i:]), op) {
+                tokens = append(tokens, filterToken{filterTokOp, op, start})
+                i += len([]rune(op))
+                found = true
+                break
+            }
+        }
+        if !found {
+            return nil, fmt.Errorf("unexpected %q at position %d", r, start+1)
+        }
+    }
+    return append(tokens, filterToken{filterTokEOF, "", len(runes)}), nil
+}
+
+// A column or a literal
+type filterOperand struct {
+    col  int // -1 for a literal
+    text string
+}
+
+func (o filterOperand) value(row int) string {
+    if o.col < 0 {
+        return o.text
+    }
+    return strings.TrimSpace(cellText(row, o.col))
+}
+
+type filterNode interface {
+    eval(row int) bool
+}
+
+type filterAnd struct{ a, b filterNode }
+type filterOr struct{ a, b filterNode }
+type filterNot struct{ a filterNode }
+type filterNotEmpty struct{ a filterOperand }
+
+type filterCompare struct {
+    op          string
+    left, right filterOperand
+}
+
+type filterMatch struct {
+    left   filterOperand
+    re     *regexp.Regexp
+    negate bool
+}
+
+func (n filterAnd) eval(row int) bool      { return n.a.eval(row) && n.b.eval(row) }
+func (n filterOr) eval(row int) bool       { return n.a.eval(row) || n.b.eval(row) }
+func (n filterNot) eval(row int) bool      { return !n.a.eval(row) }
+func (n filterNotEmpty) eval(row int) bool { return n.a.value(row) != "" }
+func (n filterMatch) eval(row int) bool    { return n.re.MatchString(n.left.value(row)) != n.negate }
+
+// Numbers, then dates, then case insensitive ( natural ) text
+func compareFilterValues(a string, b string) int {
+    if x, ok := parseSortNumber(a); ok {
+        if y, ok := parseSortNumber(b); ok {
+            switch {
+            case x < y:
+                return -1
+            case x > y:
+                return 1
+            }
+            return 0
+        }
+    }
+    if x, ok := parseSortDate(a); ok {
+        if y, ok := parseSortDate(b); ok {
+            return x.Compare(y)
+        }
+    }
+    if strings.EqualFold(a, b) {
+        return 0
+    }
+    return naturalCompare(a, b)
+}
+
+func (n filterCompare) eval(row int) bool {
+    a, b := n.left.value(row), n.right.value(row)
+    switch n.op {
+    case "contains":
+        return strings.Contains(strings.ToLower(a), strings.ToLower(b))
+    case "startswith":
+        return strings.HasPrefix(strings.ToLower(a), strings.ToLower(b))
+    case "endswith":
+        return strings.HasSuffix(strings.ToLower(a), strings.ToLower(b))
+    }
+
+    c := compareFilterValues(a, b)
+    switch n.op {
+    case "==", "=":
+        return c == 0
+    case "!=", "<>":
+        return c != 0
+    case "<":
+        return c < 0
+    case "<=":
+        return c <= 0
+    case ">":
+        return c > 0
+    case ">=":
+        return c >= 0
+    }
+    return false
+}
+
+type filterParser struct {
+    tokens []filterToken
+    pos    int
+}
+
+func (p *filterParser) peek() filterToken { return p.tokens[p.pos] }
+
+func (p *filterParser) next() filterToken {
+    t := p.tokens[p.pos]
+    if t.kind != filterTokEOF {
+        p.pos++
+    }
+    return t
+}
+
+// Consume the operator if it is one of ops
+func (p *filterParser) accept(ops ...string) (string, bool) {
+    t := p.peek()
+    if t.kind != filterTokOp {
+        return "", false
+    }
+    for _, op := range ops {
+        if t.text == op {
+            p.pos++
+            return op, true
+        }
+    }
+    return "", false
+}
+
+func (p *filterParser) errorf(t filterToken, format string, args ...interface{}) error {
+    if t.kind == filterTokEOF {
+        return fmt.Errorf(format+" at the end", args...)
+    }
+    return fmt.Errorf(format+" at position %d", append(args, t.pos+1)...)
+}
+
+func (p *filterParser) parseOr() (filterNode, error) {
+    left, err := p.parseAnd()
+    if err != nil {
+        return nil, err
+    }
+    for {
+        if _, ok := p.accept("||", "or"); !ok {
+            return left, nil
+        }
+        right, err := p.parseAnd()
+        if err != nil {
+            return nil, err
+        }
+        left = filterOr{left, right}
+    }
+}
+
+func (p *filterParser) parseAnd() (filterNode, error) {
+    left, err := p.parseNot()
+    if err != nil {
+        return nil, err
+    }
+    for {
+        if _, ok := p.accept("&&", "and"); !ok {
+            return left, nil
+        }
+        right, err := p.parseNot()
+        if err != nil {
+            return nil, err
+        }
+        left = filterAnd{left, right}
+    }
+}
+
+func (p *filterParser) parseNot() (filterNode, error) {
+    if _, ok := p.accept("!", "not"); ok {
+        a, err := p.parseNot()
+        if err != nil {
+            return nil, err
+        }
+        return filterNot{a}, nil
+    }
+    if _, ok := p.accept("("); ok {
+        node, err := p.parseOr()
+        if err != nil {
+            return nil, err
+        }
+        if _, ok := p.accept(")"); !ok {
+            return nil, p.errorf(p.peek(), "expected )")
+        }
+        return node, nil
+    }
+    return p.parseComparison()
+}
+
+// right: a word that is not a column name is taken as text ( Status == open )
+func (p *filterParser) parseOperand(right bool) (filterOperand, error) {
+    t := p.next()
+    switch t.kind {
+    case filterTokString, filterTokNumber:
+        return filterOperand{col: -1, text: t.text}, nil
+    case filterTokColumn:
+        name := t.text
+        if strings.HasPrefix(name, "$") {
+            name = name[1:]
+        } else if _, err := strconv.Atoi(name); err == nil {
+            // `3` is a header named "3", not column 3
+            for c := 0; c < numCols; c++ {
+                if strings.TrimSpace(cellText(0, c)) == name {
+                    return filterOperand{col: c}, nil
+                }
+            }
+        }
+        col, err := columnIndex(name)
+        if err != nil {
+            if right && isFilterIdentRune([]rune(t.text)[0], true) {
+                return filterOperand{col: -1, text: t.text}, nil
+            }
+            return filterOperand{}, p.errorf(t, "%v", err)
+        }
+        return filterOperand{col: col}, nil
+    }
+    return filterOperand{}, p.errorf(t, "expected a column or a value")
+}
+
+func (p *filterParser) parseComparison() (filterNode, error) {
+    left, err := p.parseOperand(false)
+    if err != nil {
+        return nil, err
+    }
+
+    t := p.peek()
+    op, ok := p.accept("==", "=", "!=", "<>", "<", "<=", ">", ">=", "contains", "startswith", "endswith", "=~", "!~")
+    if !ok {
+        return filterNotEmpty{left}, nil
+    }
+
+    if op == "=~" || op == "!~" {
+        pt := p.next()
+        if pt.kind != filterTokString {
+            return nil, p.errorf(pt, "expected a \"pattern\" after %s", op)
+        }
+        re, err := regexp.Compile(pt.text)
+        if err != nil {
+            return nil, p.errorf(t, "bad pattern: %v", err)
+        }
+        return filterMatch{left: left, re: re, negate: op == "!~"}, nil
+    }
+
+    right, err := p.parseOperand(true)
+    if err != nil {
+        return nil, err
+    }
+    return filterCompare{op: op, left: left, right: right}, nil
+}
+
+func compileFilter(text string) (filterNode, error) {
+    tokens, err := tokenizeFilter(text)
+    if err != nil {
+        return nil, err
+    }
+    p := &filterParser{tokens: tokens}
+    node, err := p.parseOr()
+    if err != nil {
+        return nil, err
+    }
+    if t := p.peek(); t.kind != filterTokEOF {
+        return nil, p.errorf(t, "unexpected %q", t.text)
+    }
+    return node, nil
+}
+
+// Applying
+
+// Show only the rows matching text, an empty text shows every row
+func applyFilter(text string) error {
+    text = strings.TrimSpace(text)
+    if text == "" {
+        clearFilter()
+        return nil
+    }
+    if loadBusy() {
+        return fmt.Errorf("file is still loading")
+    }
+
+    node, err := compileFilter(text)
+    if err != nil {
+        return err
+    }
+
+    rows := []int{0}
+    for r := 1; r < len(data); r++ {
+        if node.eval(r) {
+            rows = append(rows, r)
+        }
+    }
+
+    filterActive = true
+    filterText = text
+    viewRows = rows
+    renderTable()
+    return nil
+}
+
+func clearFilter() {
+    if !filterActive {
+        return
+    }
+    filterActive = false
+    filterText = ""
+    viewRows = nil
+    renderTable()
+}
+
+// Shown in the status bar
+func filterStatus() string {
+    if !filterActive {
+        return ""
+    }
+    return fmt.Sprintf("filtered %d of %d rows", len(viewRows)-1, len(data)-1)
+}
+
+// Prompt
+
+func filterInit() {
+    filterField = tview.NewInputField()
+    filterField.SetLabel("filter: ")
+
+    // Filter while typing, as long as the expression is complete
+    filterField.SetChangedFunc(func(text string) {
+        if strings.TrimSpace(text) == "" {
+            clearFilter()
+            return
+        }
+        if err := applyFilter(text); err != nil {
+            showMessage("%v", err)
+            return
+        }
+        clearMessage()
+    })
+
+    filterField.SetDoneFunc(func(key tcell.Key) {
+        if key == tcell.KeyTab || key == tcell.KeyBacktab {
+            return
+        }
+        closeFilter()
+
+        if key == tcell.KeyEnter {
+            if err := applyFilter(filterField.GetText()); err != nil {
+                showMessage("Filter: %v", err)
+                restoreFilter()
+            }
+        } else {
+            restoreFilter()
+        }
+        refreshTable()
+    })
+
+    registerFilterCommands()
+}
+
+func openFilter() {
+    if loadBusy() {
+        return
+    }
+    editing = true
+    filterPrevText = filterText
+    filterField.SetText(filterText)
+    flex.AddItem(filterField, 3, 0, true)
+    app.SetFocus(filterField)
+}
+
+func closeFilter() {
+    editing = false
+    flex.RemoveItem(filterField)
+    app.SetFocus(table)
+}
+
+// Back to the filter that was active when the prompt was opened
+func restoreFilter() {
+    if filterPrevText == "" {
+        clearFilter()
+        return
+    }
+    applyFilter(filterPrevText)
+}
+
+func registerFilterCommands() {
+    registerCommand(&command{
+        name: "filter",
+        args: "[expression]",
+        help: "Show only the rows matching the expression ( without one: apply the current filter again )",
+        run: func(bang bool, args string) error {
+            text := strings.TrimSpace(args)
+            if text == "" {
+                if !filterActive {
+                    return fmt.Errorf("no filter")
+                }
+                text = filterText
+            }
+            return applyFilter(text)
+        },
+    })
+    registerCommand(&command{
+        name:    "nofilter",
+        aliases: []string{"nof"},
+        help:    "Show all rows again",
+        run: func(bang bool, args string) error {
+            clearFilter()
+            return nil
+        },
+    })
+}
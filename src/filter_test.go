@@ -0,0 +1,158 @@
+/**
+ * MIT License
+ *
+ * Copyright (c) 2025 Viki (VN - initials of my first and last name)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ *
+ * Contact: contact@viki.design
+ * Website: https://www.viki.design
+ *
+ */
+
+package main
+import (
+    "reflect"
+    "strings"
+    "testing"
+)
+
+// Rows for the tests that do not need the app, put back after the test
+func useTestData(t *testing.T, rows [][]string) {
+    oldData, oldCols := data, numCols
+    t.Cleanup(func() { data, numCols = oldData, oldCols })
+    data = rows
+    numCols = len(rows[0])
+}
+
+func TestTokenizeFilter(t *testing.T) {
+    tests := []struct {
+        text string
+        want []string
+    }{
+        {`Status != "done" && $2 >= 2`, []string{"Status", "!=", "done", "&&", "$2", ">=", "2"}},
+        {"`Due date` < 2025-11-01", []string{"Due date", "<", "2025-11-01"}},
+        {`a=b`, []string{"a", "=", "b"}},
+        {`a AND NOT b`, []string{"a", "and", "not", "b"}},
+        {`x == 'it''s'`, []string{"x", "==", "it", "s"}},
+        // A backslash escapes the quote only, patterns keep theirs
+        {`x == "say \"hi\""`, []string{"x", "==", `say "hi"`}},
+        {`x == 'it\'s'`, []string{"x", "==", "it's"}},
+        {`x =~ "\.com$"`, []string{"x", "=~", `\.com$`}},
+        {`x =~ "a\\b"`, []string{"x", "=~", `a\\b`}},
+        {`x =~ '"\w"'`, []string{"x", "=~", `"\w"`}},
+        {`x == ""`, []string{"x", "==", ""}},
+        {`x > -1.5`, []string{"x", ">", "-1.5"}},
+    }
+    for _, tt := range tests {
+        tokens, err := tokenizeFilter(tt.text)
+        if err != nil {
+            t.Errorf("tokenizeFilter(%q): %v", tt.text, err)
+            continue
+        }
+        var got []string
+        for _, tok := range tokens[:len(tokens)-1] {
+            got = append(got, tok.text)
+        }
+        if !reflect.DeepEqual(got, tt.want) {
+            t.Errorf("tokenizeFilter(%q) = %q, want %q", tt.text, got, tt.want)
+        }
+    }
+}
+
+func TestCompileFilter(t *testing.T) {
+    useTestData(t, [][]string{
+        {"name", "age", "city", "email", "Due date"},
+        {"ann", "30", "Rome", "ann@x.com", "2025-10-01"},
+        {"bob", "40", "Oslo", "bob@comx.org", "2025-12-01"},
+        {"carl", "25", "Lima", "", ""},
+        {"dora", "", "rome", `dora"@z.com`, "2025-11-01"},
+    })
+    tests := []struct {
+        text string
+        want []int
+    }{
+        {"age >= 30", []int{1, 2}},
+        {"age = 9", nil},
+        {"city == rome", []int{1, 4}},
+        {"City <> 'rome'", []int{2, 3}},
+        {"email", []int{1, 2, 4}},
+        {"!email", []int{3}},
+        {"`Due date` < 2025-11-01", []int{1, 3}},
+        {"`Due date` and `Due date` < 2025-11-01", []int{1}},
+        {"$1 startswith 'c' || $1 endswith \"a\"", []int{3, 4}},
+        {"name contains O", []int{2, 4}},
+        // Escapes
+        {`email =~ "\.com$"`, []int{1, 4}},
+        {`email !~ "\.com$"`, []int{2, 3}},
+        {`email contains "a\"@"`, []int{4}},
+        // and binds tighter than or, not tighter than and
+        {"name == bob or name == ann and age > 30", []int{2}},
+        {"(name == bob or name == ann) and age >= 30", []int{1, 2}},
+        {"not city == rome and age", []int{2, 3}},
+        {"not (city == rome and age)", []int{2, 3, 4}},
+        {"! ! email", []int{1, 2, 4}},
+    }
+    for _, tt := range tests {
+        node, err := compileFilter(tt.text)
+        if err != nil {
+            t.Errorf("compileFilter(%q): %v", tt.text, err)
+            continue
+        }
+        var got []int
+        for row := 1; row < len(data); row++ {
+            if node.eval(row) {
+                got = append(got, row)
+            }
+        }
+        if !reflect.DeepEqual(got, tt.want) {
+            t.Errorf("compileFilter(%q) matches rows %v, want %v", tt.text, got, tt.want)
+        }
+    }
+}
+
+func TestCompileFilterErrors(t *testing.T) {
+    useTestData(t, [][]string{{"name", "age"}, {"ann", "30"}})
+    tests := []struct {
+        text string
+        want string
+    }{
+        {`name == "ann`, "missing closing \" at position 9"},
+        {`name == "ann\"`, "missing closing \" at position 9"},
+        {"`name", "missing closing ` at position 1"},
+        {"name # 1", `unexpected '#' at position 6`},
+        {"size > 1", `no column named "size" at position 1`},
+        {"$3 > 1", "no column 3 at position 1"},
+        {"name ==", "expected a column or a value at the end"},
+        {"(name == ann", "expected ) at the end"},
+        {"name == ann)", `unexpected ")" at position 12`},
+        {"name == ann age", `unexpected "age" at position 13`},
+        {"name =~ ann", `expected a "pattern" after =~ at position 9`},
+        {`name =~ "("`, "bad pattern"},
+        {"and", "expected a column or a value at position 1"},
+    }
+    for _, tt := range tests {
+        _, err := compileFilter(tt.text)
+        if err == nil || !strings.Contains(err.Error(), tt.want) {
+            t.Errorf("compileFilter(%q) error = %v, want %q", tt.text, err, tt.want)
+        }
+    }
+}
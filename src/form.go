@@ -0,0 +1,315 @@
+/**
+ * MIT License
+ *
+ * Copyright (c) 2025 Viki (VN - initials of my first and last name)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ *
+ * Contact: contact@viki.design
+ * Website: https://www.viki.design
+ *
+ */
+
+package main
+
+/*
+  Record form:
+
+    r shows the current row as a form, one field per column labelled with the
+    header, for files too wide to edit in the table. PgUp / PgDn ( Ctrl+P /
+    Ctrl+N ) go to the previous / next record, with a filter active only the
+    visible ones. Long cells and cells with line breaks get a text area.
+
+    Columns of numbers or dates ( detected like the sort does, see sort.go )
+    only take numbers or dates, empty fields are always fine. The fields are
+    checked on Ctrl+S and before going to another record, a wrong field keeps
+    the form on the record, gets the focus and is named in the title.
+
+    The changed fields of a record are written as one batchOp, one Ctrl+Z
+    puts the whole row back. Esc closes the form ( twice when there are
+    changes not saved ).
+*/
+
+import (
+    "fmt"
+    "strings"
+
+    "github.com/gdamore/tcell/v2"
+    "github.com/rivo/tview"
+)
+
+// Longest label, the fields get the rest of the width
+const formLabelWidth = 24
+
+type recordField struct {
+    col  int
+    kind int
+    item tview.FormItem
+}
+
+func (f recordField) text() string {
+    switch item := f.item.(type) {
+    case *tview.InputField:
+        return item.GetText()
+    case *tview.TextArea:
+        return item.GetText()
+    }
+    return ""
+}
+
+// Check a field against the type of its column
+func (f recordField) check() error {
+    text := strings.TrimSpace(f.text())
+    if text == "" {
+        return nil
+    }
+    switch f.kind {
+    case sortNumber:
+        if _, ok := parseSortNumber(text); !ok {
+            return fmt.Errorf("%s: \"%s\" is not a number", formLabel(f.col), text)
+        }
+    case sortDate:
+        if _, ok := parseSortDate(text); !ok {
+            return fmt.Errorf("%s: \"%s\" is not a date", formLabel(f.col), text)
+        }
+    }
+    return nil
+}
+
+func formLabel(col int) string {
+    label := strings.TrimSpace(strings.ReplaceAll(cellText(0, col), "\n", " "))
+    if label == "" {
+        label = fmt.Sprintf("Column %d", col+1)
+    }
+    if runes := []rune(label); len(runes) > formLabelWidth {
+        label = string(runes[:formLabelWidth-1]) + "…"
+    }
+    return label
+}
+
+// Records ( data rows, the header is row 0 ) the form pages through
+func formRecords() []int {
+    rows := []int{}
+    for row := 1; row < len(data); row++ {
+        if rowVisible(row) {
+            rows = append(rows, row)
+        }
+    }
+    return rows
+}
+
+// Number / date / text for every column, from the cells of all records
+func formColumnKinds(records []int) []int {
+    kinds := make([]int, numCols)
+    for col := range kinds {
+        kinds[col] = detectSortKind(col, records)
+    }
+    return kinds
+}
+
+// r: edit the current row as a form
+func showRecordForm() {
+    records := formRecords()
+    if len(records) == 0 || numCols == 0 {
+        showMessage("No records to show")
+        return
+    }
+    kinds := formColumnKinds(records)
+
+    // Start at the cursor, on the header at the first record
+    index := 0
+    for i, row := range records {
+        if row <= selectedRow {
+            index = i
+        }
+    }
+
+    form := tview.NewForm()
+    form.SetItemPadding(0).
+        SetFieldBackgroundColor(tcell.ColorDarkBlue).
+        SetBorder(true)
+
+    var fields []recordField
+    // Title shows a warning ( wrong field, changes not saved ) until the next key
+    warned, discardArmed := false, false
+    row := records[index]
+
+    title := func() string {
+        return fmt.Sprintf(" Record %d of %d ( row %d )  PgUp / PgDn: previous / next, Ctrl+S: save, Esc: close ", index+1, len(records), row)
+    }
+    changed := func() bool {
+        for _, f := range fields {
+            if f.text() != cellText(row, f.col) {
+                return true
+            }
+        }
+        return false
+    }
+    load := func() {
+        row = records[index]
+        warned, discardArmed = false, false
+        form.Clear(false)
+        fields = fields[:0]
+        for col := 0; col < numCols; col++ {
+            label := tview.Escape(formLabel(col))
+            switch kinds[col] {
+            case sortNumber:
+                label += " ( number )"
+            case sortDate:
+                label += " ( date )"
+            }
+            text := cellText(row, col)
+            var item tview.FormItem
+            if needsTextEditor(text) {
+                height := strings.Count(text, "\n") + 2
+                if height > 6 {
+                    height = 6
+                }
+                area := tview.NewTextArea().SetWrap(true).SetWordWrap(true)
+                area.SetLabel(label)
+                area.SetText(text, false)
+                area.SetSize(height, 0)
+                item = area
+            } else {
+                item = tview.NewInputField().SetLabel(label).SetText(text)
+            }
+            form.AddFormItem(item)
+            fields = append(fields, recordField{col: col, kind: kinds[col], item: item})
+        }
+        form.SetTitle(title())
+        form.SetFocus(0)
+
+        // Keep the table cursor on the record
+        selectedRow = row
+        table.Select(viewRowOf(row), selectedCol)
+    }
+
+    // Write the changed fields as one change, false when a field is wrong
+    save := func() bool {
+        for i, f := range fields {
+            if err := f.check(); err != nil {
+                form.SetFocus(i)
+                app.SetFocus(form)
+                form.SetTitle(fmt.Sprintf(" %s ", tview.Escape(err.Error())))
+                warned = true
+                showMessage("%v", err)
+                return false
+            }
+        }
+        ops := batchOp{}
+        for _, f := range fields {
+            old, text := cellText(row, f.col), f.text()
+            if text != old {
+                ops = append(ops, &setCellOp{row: row, col: f.col, oldText: old, newText: text})
+                warnUnrepresentable(text)
+            }
+        }
+        if len(ops) > 0 {
+            if !runOp(ops, row, selectedCol) {
+                app.SetFocus(form)
+                return false
+            }
+            showMessage("Record %d saved ( %d field(s) changed )", index+1, len(ops))
+        }
+        warned, discardArmed = false, false
+        form.SetTitle(title())
+        app.SetFocus(form)
+        return true
+    }
+    move := func(step int) {
+        if index+step < 0 || index+step >= len(records) || !save() {
+            return
+        }
+        index += step
+        load()
+        app.SetFocus(form)
+    }
+    closeForm := func() {
+        pages.RemovePage("form")
+        app.SetFocus(table)
+    }
+
+    form.AddButton("Save", func() { save() }).
+        AddButton("Previous", func() { move(-1) }).
+        AddButton("Next", func() { move(1) }).
+        AddButton("Close", func() {
+            if save() {
+                closeForm()
+            }
+        })
+
+    form.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+        switch event.Key() {
+        case tcell.KeyCtrlS:
+            save()
+            return nil
+        case tcell.KeyPgDn, tcell.KeyCtrlN:
+            move(1)
+            return nil
+        case tcell.KeyPgUp, tcell.KeyCtrlP:
+            move(-1)
+            return nil
+        case tcell.KeyEscape:
+            if changed() && !discardArmed {
+                warned, discardArmed = true, true
+                form.SetTitle(" Changes not saved: Esc again discards them, Ctrl+S saves ")
+                return nil
+            }
+            closeForm()
+            return nil
+        }
+        if warned {
+            warned, discardArmed = false, false
+            form.SetTitle(title())
+        }
+        return event
+    })
+    load()
+
+    popup := tview.NewFlex().
+        SetDirection(tview.FlexRow).
+        AddItem(nil, 1, 0, false).
+        AddItem(
+            tview.NewFlex().
+                AddItem(nil, 4, 0, false).
+                AddItem(form, 0, 1, true).
+                AddItem(nil, 4, 0, false),
+            0, 1, true).
+        AddItem(nil, 2, 0, false)
+    pages.AddPage("form", popup, true, true)
+    app.SetFocus(form)
+}
+
+func registerFormCommands() {
+    registerCommand(&command{
+        name: "form", args: "[row]",
+        help: "Edit the selected ( or that ) row as a form, same as r",
+        run: func(bang bool, args string) error {
+            if strings.TrimSpace(args) != "" {
+                if err := cmdGoto(false, args); err != nil {
+                    return err
+                }
+            }
+            showRecordForm()
+            return nil
+        },
+    })
+}
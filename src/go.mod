@@ -13,5 +13,5 @@ require (
 	github.com/mattn/go-runewidth v0.0.19 // indirect
 	golang.org/x/sys v0.35.0 // indirect
 	golang.org/x/term v0.34.0 // indirect
-	golang.org/x/text v0.28.0 // indirect
+	golang.org/x/text v0.28.0
 )
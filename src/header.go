@@ -0,0 +1,206 @@
+/**
+ * MIT License
+ *
+ * Copyright (c) 2025 Viki (VN - initials of my first and last name)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ *
+ * Contact: contact@viki.design
+ * Website: https://www.viki.design
+ *
+ */
+
+package main
+
+/*
+  Header row:
+
+    Row 0 of `data` is always the header row: it stays on top when sorting,
+    can not be deleted and names the columns for :sort and :filter. A file
+    without a header ( -no-header, header:no in the config file, or detected
+    from the data ) gets column labels in row 0 instead: A, B, C ... or
+    1, 2, 3 ... ( :set labels=numbers ). The labels are not part of the file,
+    they can not be edited and are not saved.
+
+    :header makes the selected row the header ( a real header moves down
+    into the data as the first row ), :noheader moves the header into the
+    data and shows labels. Both are undone with one Ctrl+Z. The choice is
+    stored in the config file of the csv file ( header:yes / header:no ) when
+    the file is saved, so the file opens the same way next time ( and a
+    journal of unsaved changes is replayed on the file as it was saved ).
+*/
+
+import (
+    "fmt"
+
+    "github.com/gdamore/tcell/v2"
+    "github.com/rivo/tview"
+
+    "neoviki_spreadsheet/modules_neoviki/document"
+)
+
+var (
+    noHeaderFlag bool
+
+    // "letters" or "numbers" ( labels: in the config file )
+    labelStyle = "letters"
+
+    // :header / :noheader since the last save
+    headerChoiceChanged bool
+)
+
+// Label of column c: A .. Z, AA, AB ... or 1, 2, 3 ...
+func columnLabel(c int) string {
+    if labelStyle == "numbers" {
+        return document.ColumnNumbers(c)
+    }
+    return document.ColumnLetters(c)
+}
+
+// Renew the labels in row 0 ( after :set labels ), nothing to do for a real header
+func relabelHeader() {
+    doc.Relabel()
+}
+
+// Labels are shown in another color than a header from the file
+func styleHeaderCell(cell *tview.TableCell) {
+    if doc.HasHeader() {
+        cell.SetTextColor(tcell.ColorYellow)
+    } else {
+        cell.SetTextColor(tcell.ColorDarkCyan)
+    }
+}
+
+// The labels are not data
+func headerReadOnly(row int) bool {
+    if row == 0 && !doc.HasHeader() {
+        showMessage("The column labels are not part of the file ( :header makes the selected row the header )")
+        return true
+    }
+    return false
+}
+
+// Make row the header, a header from the file moves down to row 1
+func promoteRow(row int) {
+    if row < 0 || row >= len(data) {
+        return
+    }
+    if row == 0 {
+        if doc.HasHeader() {
+            showMessage("This row is the header already")
+        } else {
+            showMessage("Select the row that should become the header")
+        }
+        return
+    }
+    if loadBusy() {
+        return
+    }
+
+    var ops batchOp
+    at := row
+    if doc.HasHeader() {
+        ops = append(ops, &insertRowOp{at: 1, cells: data[0], format: rowFormats[0]})
+        at++
+    }
+    ops = append(ops,
+        &deleteRowOp{at: at},
+        &setHeaderOp{hasHeader: true, cells: data[row], format: rowFormats[row]})
+    if !runOp(ops, 0, selectedCol) {
+        return
+    }
+    showMessage("Row %d is the header now", row)
+}
+
+// Move the header into the data and show labels instead
+func demoteHeader() {
+    if !doc.HasHeader() {
+        showMessage("There is no header row ( :header makes the selected row the header )")
+        return
+    }
+    if loadBusy() {
+        return
+    }
+    ops := batchOp{
+        &insertRowOp{at: 1, cells: data[0], format: rowFormats[0]},
+        &setHeaderOp{hasHeader: false, cells: data[0]},
+    }
+    if !runOp(ops, 1, selectedCol) {
+        return
+    }
+    showMessage("The header is row 1 now, the columns are labeled %s", labelStyle)
+}
+
+func headerChanged() {
+    headerChoiceChanged = true
+}
+
+// Keep the choice for the next time the file is opened, called after a save
+func saveHeaderChoice(filename string) {
+    if !headerChoiceChanged {
+        return
+    }
+    value := "no"
+    if doc.HasHeader() {
+        value = "yes"
+    }
+    if err := setConfigValue(filename, "header", value); err != nil {
+        showMessage("Config: %v", err)
+        return
+    }
+    if filename == inputFile {
+        headerChoiceChanged = false
+    }
+}
+
+func registerHeaderCommands() {
+    registerCommand(&command{
+        name: "header",
+        help: "Make the selected row the header row",
+        run: func(bang bool, args string) error {
+            row, _ := currentCell()
+            promoteRow(row)
+            return nil
+        },
+    })
+    registerCommand(&command{
+        name: "noheader",
+        help: "Move the header row into the data and label the columns A, B, C ...",
+        run: func(bang bool, args string) error {
+            demoteHeader()
+            return nil
+        },
+    })
+    registerOption(&option{
+        name: "labels",
+        help: "Column labels of files without a header: letters or numbers",
+        get:  func() string { return labelStyle },
+        set: func(value string) error {
+            if value != "letters" && value != "numbers" {
+                return fmt.Errorf("labels are letters or numbers")
+            }
+            labelStyle = value
+            relabelHeader()
+            refreshTable()
+            return setConfigValue(inputFile, "labels", value)
+        },
+    })
+}
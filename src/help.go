@@ -0,0 +1,205 @@
+/**
+ * MIT License
+ *
+ * Copyright (c) 2025 Viki (VN - initials of my first and last name)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ *
+ * Contact: contact@viki.design
+ * Website: https://www.viki.design
+ *
+ */
+
+package main
+
+/*
+  Help page:
+
+    h / ? ( or :help ) show every key, command and option on a page of its
+    own. The list is made from the keymap of the file and the command and
+    option registries when the page opens, so it shows the keys as they are
+    bound ( keymap.go ) and never misses a command.
+
+    Typing filters the list ( a row is shown when it contains all the words
+    typed ), ↑ ↓ PgUp PgDn scroll, Esc / Enter close the page.
+    :helpexport <file> writes the list as csv.
+*/
+
+import (
+    "encoding/csv"
+    "fmt"
+    "os"
+    "strings"
+
+    "github.com/gdamore/tcell/v2"
+    "github.com/rivo/tview"
+)
+
+// A row of the help page
+type helpEntry struct {
+    kind string // key, command or option
+    keys string
+    name string
+    help string
+}
+
+// Keys, commands and options as they are now
+func helpEntries() []helpEntry {
+    var entries []helpEntry
+    for _, a := range actions {
+        var keys []string
+        for _, chord := range keymap.chords() {
+            for _, name := range keymap.bindings[chord] {
+                if name == a.name {
+                    keys = append(keys, chord)
+                }
+            }
+        }
+        entries = append(entries, helpEntry{"key", strings.Join(keys, ", "), a.name, a.help})
+    }
+    for _, c := range commands {
+        keys := ":" + strings.Join(append([]string{c.name}, c.aliases...), " :")
+        if c.args != "" {
+            keys += " " + c.args
+        }
+        entries = append(entries, helpEntry{"command", keys, c.name, c.help})
+    }
+    for _, o := range options {
+        entries = append(entries, helpEntry{"option", ":set " + o.name, o.name, fmt.Sprintf("%s ( now %s )", o.help, o.get())})
+    }
+    return entries
+}
+
+// Entries that contain every word of filter ( case-insensitive )
+func filterHelp(entries []helpEntry, filter string) []helpEntry {
+    words := strings.Fields(strings.ToLower(filter))
+    var shown []helpEntry
+    for _, e := range entries {
+        text := strings.ToLower(strings.Join([]string{e.kind, e.keys, e.name, e.help}, " "))
+        match := true
+        for _, w := range words {
+            if !strings.Contains(text, w) {
+                match = false
+                break
+            }
+        }
+        if match {
+            shown = append(shown, e)
+        }
+    }
+    return shown
+}
+
+func showHelp(filter string) {
+    entries := helpEntries()
+
+    list := tview.NewTable().SetFixed(1, 0).SetSelectable(true, false)
+    list.SetBorder(true)
+    input := tview.NewInputField().SetLabel(" Filter: ").SetText(filter)
+
+    fill := func(text string) {
+        shown := filterHelp(entries, text)
+        list.Clear()
+        for col, title := range []string{"Kind", "Keys", "Name", "Description"} {
+            list.SetCell(0, col, tview.NewTableCell(title).SetTextColor(tcell.ColorYellow).SetSelectable(false))
+        }
+        for i, e := range shown {
+            list.SetCell(i+1, 0, tview.NewTableCell(e.kind))
+            list.SetCell(i+1, 1, tview.NewTableCell(tview.Escape(e.keys)).SetTextColor(tcell.ColorDarkCyan))
+            list.SetCell(i+1, 2, tview.NewTableCell(e.name))
+            list.SetCell(i+1, 3, tview.NewTableCell(tview.Escape(e.help)).SetExpansion(1))
+        }
+        list.SetTitle(fmt.Sprintf(" Help: %d of %d ( type to filter, ↑ ↓ PgUp PgDn scroll, Esc: close ) ", len(shown), len(entries)))
+        list.Select(1, 0)
+        list.ScrollToBeginning()
+    }
+    fill(filter)
+
+    closeHelp := func() {
+        pages.RemovePage("help")
+        app.SetFocus(table)
+    }
+    input.SetChangedFunc(fill)
+    input.SetDoneFunc(func(key tcell.Key) { closeHelp() })
+
+    // The filter has the focus, keys that scroll go to the list
+    input.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+        switch event.Key() {
+        case tcell.KeyUp, tcell.KeyDown, tcell.KeyPgUp, tcell.KeyPgDn:
+            list.InputHandler()(event, func(p tview.Primitive) {})
+            return nil
+        }
+        return event
+    })
+
+    page := tview.NewFlex().
+        SetDirection(tview.FlexRow).
+        AddItem(list, 0, 1, false).
+        AddItem(input, 1, 0, true)
+    pages.AddPage("help", page, true, true)
+    app.SetFocus(input)
+}
+
+// Write the help list as csv ( Kind, Keys, Name, Description )
+func exportHelp(path string) error {
+    f, err := os.Create(path)
+    if err != nil {
+        return err
+    }
+    w := csv.NewWriter(f)
+    w.Write([]string{"Kind", "Keys", "Name", "Description"})
+    entries := helpEntries()
+    for _, e := range entries {
+        w.Write([]string{e.kind, e.keys, e.name, e.help})
+    }
+    w.Flush()
+    if err := w.Error(); err != nil {
+        f.Close()
+        return err
+    }
+    if err := f.Close(); err != nil {
+        return err
+    }
+    showMessage("Wrote %d help entries to %s", len(entries), path)
+    return nil
+}
+
+func registerHelpCommands() {
+    registerCommand(&command{
+        name: "help", aliases: []string{"h"}, args: "[filter]",
+        help: "Show the keys, commands and options ( same as h / ? )",
+        run: func(bang bool, args string) error {
+            showHelp(args)
+            return nil
+        },
+    })
+    registerCommand(&command{
+        name: "helpexport", args: "<file>", complete: "file",
+        help: "Write the keys, commands and options as csv",
+        run: func(bang bool, args string) error {
+            files := splitArgs(args)
+            if len(files) == 0 {
+                return fmt.Errorf("helpexport needs a file name")
+            }
+            return exportHelp(files[0])
+        },
+    })
+}
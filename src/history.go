@@ -0,0 +1,330 @@
+/**
+ * MIT License
+ *
+ * Copyright (c) 2025 Viki (VN - initials of my first and last name)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ *
+ * Contact: contact@viki.design
+ * Website: https://www.viki.design
+ *
+ */
+
+package main
+
+/*
+  Undo / Redo:
+
+    Every change to the document ( see document.go ) is wrapped in an editOp.
+    An op knows how to apply itself (do / redo) and how to revert itself
+    (undo). runOp() applies an op
+    and pushes it on the undo stack together with the cursor position before
+    and after the change, so undo/redo also put the selection back.
+
+    The history is kept for the whole session ( it is not cleared on save ).
+    The depth of the undo stack at the last save is remembered, undo / redo
+    back to it makes the file unchanged again.
+*/
+
+import (
+    "neoviki_spreadsheet/modules_neoviki/csvio"
+)
+
+const defaultUndoDepth = 100
+
+var (
+    undoStack []historyEntry
+    redoStack []historyEntry
+
+    // Max number of ops kept on the undo stack ( -undo-depth / undo_depth: in config )
+    undoDepth = defaultUndoDepth
+
+    // Length of undoStack when the file was saved, -1 when undo / redo can
+    // not get back to that state anymore
+    savedDepth int
+)
+
+type editOp interface {
+    apply()
+    revert()
+
+    // Op that undoes this one ( only valid after apply )
+    inverse() editOp
+
+    // Journal records for this op ( see journal.go )
+    records() [][]string
+}
+
+type historyEntry struct {
+    op editOp
+
+    beforeRow int
+    beforeCol int
+    afterRow  int
+    afterCol  int
+}
+
+// Ops
+
+type setCellOp struct {
+    row     int
+    col     int
+    oldText string
+    newText string
+}
+
+func (op *setCellOp) apply()  { doc.SetCell(op.row, op.col, op.newText) }
+func (op *setCellOp) revert() { doc.SetCell(op.row, op.col, op.oldText) }
+func (op *setCellOp) inverse() editOp {
+    return &setCellOp{row: op.row, col: op.col, oldText: op.newText, newText: op.oldText}
+}
+
+// Replaces all cells of a row ( the row may get another length )
+type setRowOp struct {
+    row      int
+    oldCells []string
+    newCells []string
+}
+
+func (op *setRowOp) apply()  { doc.SetRow(op.row, op.newCells) }
+func (op *setRowOp) revert() { doc.SetRow(op.row, op.oldCells) }
+func (op *setRowOp) inverse() editOp {
+    return &setRowOp{row: op.row, oldCells: op.newCells, newCells: op.oldCells}
+}
+
+// Switches between a header row from the file and column labels
+type setHeaderOp struct {
+    hasHeader bool
+    cells     []string
+    format    *csvio.RowFormat
+
+    // Set by apply
+    oldHasHeader bool
+    oldCells     []string
+    oldFormat    *csvio.RowFormat
+}
+
+func (op *setHeaderOp) apply() {
+    op.oldHasHeader, op.oldCells, op.oldFormat = doc.HasHeader(), data[0], rowFormats[0]
+    doc.SetHeader(op.hasHeader, op.cells, op.format)
+}
+func (op *setHeaderOp) revert() { doc.SetHeader(op.oldHasHeader, op.oldCells, op.oldFormat) }
+func (op *setHeaderOp) inverse() editOp {
+    return &setHeaderOp{hasHeader: op.oldHasHeader, cells: op.oldCells, format: op.oldFormat}
+}
+
+type insertRowOp struct {
+    at     int
+    cells  []string
+    format *csvio.RowFormat
+}
+
+func (op *insertRowOp) apply()  { doc.InsertRow(op.at, op.cells, op.format) }
+func (op *insertRowOp) revert() { doc.DeleteRow(op.at) }
+func (op *insertRowOp) inverse() editOp { return &deleteRowOp{at: op.at} }
+
+type deleteRowOp struct {
+    at     int
+    cells  []string
+    format *csvio.RowFormat
+}
+
+func (op *deleteRowOp) apply()  { op.cells, op.format, _ = doc.DeleteRow(op.at) }
+func (op *deleteRowOp) revert() { doc.InsertRow(op.at, op.cells, op.format) }
+func (op *deleteRowOp) inverse() editOp {
+    return &insertRowOp{at: op.at, cells: op.cells, format: op.format}
+}
+
+type insertColOp struct {
+    at    int
+    cells []string
+}
+
+func (op *insertColOp) apply()  { doc.InsertColumn(op.at, op.cells) }
+func (op *insertColOp) revert() { doc.DeleteColumn(op.at) }
+func (op *insertColOp) inverse() editOp { return &deleteColOp{at: op.at} }
+
+type deleteColOp struct {
+    at    int
+    cells []string
+}
+
+func (op *deleteColOp) apply()  { op.cells, _ = doc.DeleteColumn(op.at) }
+func (op *deleteColOp) revert() { doc.InsertColumn(op.at, op.cells) }
+func (op *deleteColOp) inverse() editOp { return &insertColOp{at: op.at, cells: op.cells} }
+
+type reorderRowsOp struct {
+    from  int
+    order []int
+}
+
+func (op *reorderRowsOp) apply()  { doc.ReorderRows(op.from, op.order) }
+func (op *reorderRowsOp) revert() { doc.ReorderRows(op.from, invertOrder(op.order)) }
+func (op *reorderRowsOp) inverse() editOp {
+    return &reorderRowsOp{from: op.from, order: invertOrder(op.order)}
+}
+
+func invertOrder(order []int) []int {
+    inv := make([]int, len(order))
+    for i, o := range order {
+        inv[o] = i
+    }
+    return inv
+}
+
+// Several ops that are undone / redone as one step
+type batchOp []editOp
+
+func (ops batchOp) apply() {
+    for _, op := range ops {
+        op.apply()
+    }
+}
+
+func (ops batchOp) revert() {
+    for i := len(ops) - 1; i >= 0; i-- {
+        ops[i].revert()
+    }
+}
+
+func (ops batchOp) inverse() editOp {
+    inv := make(batchOp, len(ops))
+    for i, op := range ops {
+        inv[len(ops)-1-i] = op.inverse()
+    }
+    return inv
+}
+
+func (ops batchOp) records() [][]string {
+    var recs [][]string
+    for _, op := range ops {
+        recs = append(recs, op.records()...)
+    }
+    return recs
+}
+
+// Apply op, record it in the history and move the selection to (afterRow, afterCol)
+// False when the change was refused
+func runOp(op editOp, afterRow int, afterCol int) bool {
+    if journalBusy() {
+        return false
+    }
+    entry := historyEntry{
+        op:        op,
+        beforeRow: selectedRow,
+        beforeCol: selectedCol,
+        afterRow:  afterRow,
+        afterCol:  afterCol,
+    }
+
+    op.apply()
+    journalAppend(op)
+
+    if savedDepth > len(undoStack) {
+        // The saved state was undone, it goes away with the redo stack
+        savedDepth = -1
+    }
+    undoStack = append(undoStack, entry)
+    trimUndoStack()
+    redoStack = nil
+
+    selectedRow = afterRow
+    selectedCol = afterCol
+    refreshTable()
+    return true
+}
+
+// Drop the oldest ops beyond undoDepth, savedDepth counts from the new bottom
+func trimUndoStack() {
+    if undoDepth <= 0 || len(undoStack) <= undoDepth {
+        return
+    }
+    dropped := len(undoStack) - undoDepth
+    undoStack = undoStack[dropped:]
+    savedDepth = max(savedDepth-dropped, -1)
+}
+
+func undo() {
+    if len(undoStack) == 0 {
+        return
+    }
+    entry := undoStack[len(undoStack)-1]
+    undoStack = undoStack[:len(undoStack)-1]
+
+    entry.op.revert()
+    journalAppend(entry.op.inverse())
+    redoStack = append(redoStack, entry)
+    syncSavedState()
+
+    selectedRow = entry.beforeRow
+    selectedCol = entry.beforeCol
+    clampSelection()
+    refreshTable()
+}
+
+func redo() {
+    if len(redoStack) == 0 {
+        return
+    }
+    entry := redoStack[len(redoStack)-1]
+    redoStack = redoStack[:len(redoStack)-1]
+
+    entry.op.apply()
+    journalAppend(entry.op)
+    undoStack = append(undoStack, entry)
+    syncSavedState()
+
+    selectedRow = entry.afterRow
+    selectedCol = entry.afterCol
+    clampSelection()
+    refreshTable()
+}
+
+// After undo / redo: back at the saved state there is nothing to save and
+// nothing to recover
+func syncSavedState() {
+    if len(undoStack) != savedDepth {
+        return
+    }
+    doc.SetDirty(false)
+    journalDiscard()
+}
+
+// The file on disk has the current state
+func markSaved() {
+    savedDepth = len(undoStack)
+    doc.SetDirty(false)
+}
+
+func clampSelection() {
+    if selectedRow >= len(data) {
+        selectedRow = len(data) - 1
+    }
+    if selectedRow < 0 {
+        selectedRow = 0
+    }
+    if len(data) > 0 && selectedCol >= len(data[0]) {
+        selectedCol = len(data[0]) - 1
+    }
+    if selectedCol < 0 {
+        selectedCol = 0
+    }
+}
@@ -0,0 +1,395 @@
+/**
+ * MIT License
+ *
+ * Copyright (c) 2025 Viki (VN - initials of my first and last name)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ *
+ * Contact: contact@viki.design
+ * Website: https://www.viki.design
+ *
+ */
+
+package main
+
+/*
+  Edit journal:
+
+    Every change is appended to <file>.journal the moment it happens, so a
+    crash / SSH drop / kill does not lose the edits made since the last save.
+    The journal is a small csv file, one record per change:
+
+        csvgo-journal,1
+        set,<row>,<col>,<old text>,<new text>
+        setrow,<row>,<cell>,<cell>,...
+        header,<1: from the file / 0: labels>,<cell>,<cell>,...
+        insrow,<at>,<cell>,<cell>,...
+        delrow,<at>
+        inscol,<at>,<cell for row 0>,<cell for row 1>,...
+        delcol,<at>
+        reorder,<from>,<old index>,<old index>,...
+
+    Undo is journaled as the inverse change, so replaying the records in order
+    always rebuilds the last state. On startup a leftover journal is offered
+    for recovery; after a successful save the journal is removed.
+
+    Every session ( the file opened once ) starts with the csvgo-journal
+    record, also when it appends to a journal that is already there. Only the
+    changes after the last of them are recovered, the earlier ones were made
+    on a state of the file that was not saved.
+
+    The question about a leftover journal comes when the file is loaded
+    ( see loader.go ), until it is answered changes are refused: they would
+    be appended to the journal of the previous session.
+*/
+
+import (
+    "encoding/csv"
+    "fmt"
+    "io"
+    "os"
+    "strconv"
+
+    "github.com/gdamore/tcell/v2"
+    "github.com/rivo/tview"
+)
+
+const journalMagic = "csvgo-journal"
+const journalVersion = "1"
+
+var (
+    journalFile   *os.File
+    journalWriter *csv.Writer
+
+    // The csvgo-journal record of this session is written
+    journalStarted bool
+
+    // A leftover journal waits for checkJournal, changes wait for it too
+    journalPending bool
+)
+
+func getJournalPath(csvPath string) string {
+    return csvPath + ".journal"
+}
+
+func (op *setCellOp) records() [][]string {
+    return [][]string{{"set", strconv.Itoa(op.row), strconv.Itoa(op.col), op.oldText, op.newText}}
+}
+
+func (op *setRowOp) records() [][]string {
+    return [][]string{append([]string{"setrow", strconv.Itoa(op.row)}, op.newCells...)}
+}
+
+func (op *setHeaderOp) records() [][]string {
+    has := "0"
+    if op.hasHeader {
+        has = "1"
+    }
+    return [][]string{append([]string{"header", has}, op.cells...)}
+}
+
+func (op *insertRowOp) records() [][]string {
+    return [][]string{append([]string{"insrow", strconv.Itoa(op.at)}, op.cells...)}
+}
+
+func (op *deleteRowOp) records() [][]string {
+    return [][]string{{"delrow", strconv.Itoa(op.at)}}
+}
+
+func (op *insertColOp) records() [][]string {
+    return [][]string{append([]string{"inscol", strconv.Itoa(op.at)}, op.cells...)}
+}
+
+func (op *deleteColOp) records() [][]string {
+    return [][]string{{"delcol", strconv.Itoa(op.at)}}
+}
+
+func (op *reorderRowsOp) records() [][]string {
+    rec := make([]string, 0, len(op.order)+2)
+    rec = append(rec, "reorder", strconv.Itoa(op.from))
+    for _, o := range op.order {
+        rec = append(rec, strconv.Itoa(o))
+    }
+    return [][]string{rec}
+}
+
+// Decode one journal record, checking it against the current shape of `data`
+func decodeJournalRecord(rec []string) (editOp, error) {
+    if len(rec) < 2 {
+        return nil, fmt.Errorf("short record")
+    }
+    at, err := strconv.Atoi(rec[1])
+    if err != nil {
+        return nil, fmt.Errorf("bad position %q", rec[1])
+    }
+
+    switch rec[0] {
+    case "set":
+        if len(rec) != 5 {
+            return nil, fmt.Errorf("bad set record")
+        }
+        col, err := strconv.Atoi(rec[2])
+        if err != nil || at < 0 || at >= len(data) || col < 0 || col >= numCols {
+            return nil, fmt.Errorf("cell %s,%s out of range", rec[1], rec[2])
+        }
+        return &setCellOp{row: at, col: col, oldText: cellText(at, col), newText: rec[4]}, nil
+    case "setrow":
+        if at < 0 || at >= len(data) || len(rec)-2 > numCols {
+            return nil, fmt.Errorf("row %d can not be set", at)
+        }
+        return &setRowOp{row: at, oldCells: data[at], newCells: rec[2:]}, nil
+    case "header":
+        if len(data) == 0 || len(rec)-2 > numCols {
+            return nil, fmt.Errorf("bad header record")
+        }
+        return &setHeaderOp{hasHeader: at == 1, cells: rec[2:]}, nil
+    case "insrow":
+        // Rows may be shorter or wider than the others ( see malformed.go, tabs.go )
+        if at < 0 || at > len(data) {
+            return nil, fmt.Errorf("row %d can not be inserted", at)
+        }
+        return &insertRowOp{at: at, cells: rec[2:]}, nil
+    case "delrow":
+        if at < 0 || at >= len(data) {
+            return nil, fmt.Errorf("row %d out of range", at)
+        }
+        return &deleteRowOp{at: at}, nil
+    case "inscol":
+        if at < 0 || len(data) == 0 || at > numCols || len(rec)-2 != len(data) {
+            return nil, fmt.Errorf("column %d can not be inserted", at)
+        }
+        return &insertColOp{at: at, cells: rec[2:]}, nil
+    case "delcol":
+        if at < 0 || len(data) == 0 || at >= numCols {
+            return nil, fmt.Errorf("column %d out of range", at)
+        }
+        return &deleteColOp{at: at}, nil
+    case "reorder":
+        order := make([]int, len(rec)-2)
+        seen := make([]bool, len(order))
+        if at < 0 || at+len(order) > len(data) {
+            return nil, fmt.Errorf("rows %d..%d out of range", at, at+len(order)-1)
+        }
+        for i, s := range rec[2:] {
+            o, err := strconv.Atoi(s)
+            if err != nil || o < 0 || o >= len(order) || seen[o] {
+                return nil, fmt.Errorf("bad row order")
+            }
+            seen[o] = true
+            order[i] = o
+        }
+        return &reorderRowsOp{from: at, order: order}, nil
+    }
+    return nil, fmt.Errorf("unknown record %q", rec[0])
+}
+
+func journalOpen() bool {
+    if journalWriter != nil {
+        return true
+    }
+    if !editingInPlace() {
+        // Nothing to recover the changes on ( see pipe.go )
+        return false
+    }
+
+    path := getJournalPath(inputFile)
+    f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+    if err != nil {
+        showMessage("Error opening journal: %v", err)
+        return false
+    }
+    journalFile = f
+    journalWriter = csv.NewWriter(f)
+
+    if !journalStarted {
+        journalWriter.Write([]string{journalMagic, journalVersion})
+        journalStarted = true
+    }
+    return true
+}
+
+// A file was opened, its first change starts a session in the journal
+func journalSessionStart() {
+    journalStarted = false
+    journalPending = editingInPlace() && readJournal(getJournalPath(inputFile)) != nil
+}
+
+// Changes wait until the question about a leftover journal is answered
+func journalBusy() bool {
+    if journalPending {
+        showMessage("Not changed: changes of a previous session wait for recovery ( after loading )")
+        return true
+    }
+    return false
+}
+
+func journalAppend(op editOp) {
+    if !journalOpen() {
+        return
+    }
+
+    journalWriter.WriteAll(op.records())
+
+    if err := journalWriter.Error(); err != nil {
+        showMessage("Error writing journal: %v", err)
+        return
+    }
+    // Make sure the change is on disk before we go on
+    journalFile.Sync()
+}
+
+func journalClose() {
+    if journalFile != nil {
+        journalFile.Close()
+    }
+    journalFile = nil
+    journalWriter = nil
+}
+
+// Called after the csv file was saved: the journal is not needed anymore
+func journalDiscard() {
+    journalClose()
+    journalStarted = false
+    if !editingInPlace() {
+        return
+    }
+    err := os.Remove(getJournalPath(inputFile))
+    if err != nil && !os.IsNotExist(err) {
+        showMessage("Error removing journal: %v", err)
+    }
+}
+
+// Read a leftover journal, returns nil if there is nothing to recover
+func readJournal(path string) [][]string {
+    f, err := os.Open(path)
+    if err != nil {
+        return nil
+    }
+    defer f.Close()
+
+    r := csv.NewReader(f)
+    r.FieldsPerRecord = -1
+
+    var recs [][]string
+    for {
+        rec, err := r.Read()
+        if err == io.EOF {
+            break
+        }
+        if err != nil {
+            // The last record may be cut off by the crash
+            break
+        }
+        recs = append(recs, rec)
+    }
+
+    if len(recs) == 0 || recs[0][0] != journalMagic {
+        return nil
+    }
+    // The changes of the last session
+    last := 0
+    for i, rec := range recs {
+        if rec[0] == journalMagic {
+            last = i
+        }
+    }
+    if last == len(recs)-1 {
+        return nil
+    }
+    return recs[last+1:]
+}
+
+// Replay journal records onto the freshly loaded data as one undoable change.
+// The journal is rewritten with the changes that could be applied.
+func replayJournal(recs [][]string) (int, error) {
+    var ops batchOp
+    var err error
+
+    for _, rec := range recs {
+        var op editOp
+        op, err = decodeJournalRecord(rec)
+        if err != nil {
+            break
+        }
+        op.apply()
+        ops = append(ops, op)
+    }
+
+    journalDiscard()
+    if len(ops) > 0 {
+        journalAppend(ops)
+        undoStack = append(undoStack, historyEntry{
+            op:        ops,
+            beforeRow: selectedRow,
+            beforeCol: selectedCol,
+            afterRow:  selectedRow,
+            afterCol:  selectedCol,
+        })
+        redoStack = nil
+        clampSelection()
+    }
+    return len(ops), err
+}
+
+// Offer to recover the edits of a session that did not end with a save
+func checkJournal() {
+    if !editingInPlace() {
+        return
+    }
+    path := getJournalPath(inputFile)
+    recs := readJournal(path)
+    if recs == nil {
+        // Nothing usable in it ( or no journal at all )
+        journalPending = false
+        os.Remove(path)
+        return
+    }
+
+    text := fmt.Sprintf("Found %d unsaved change(s) from a previous session.\nRecover them?", len(recs))
+    modal := tview.NewModal().
+        SetText(text).
+        AddButtons([]string{"Recover", "Discard"}).
+        SetButtonBackgroundColor(tcell.ColorDarkCyan).
+        SetButtonStyle(tcell.StyleDefault.
+            Foreground(tcell.ColorWhite).
+            Background(tcell.ColorDarkCyan)).
+        SetButtonActivatedStyle(tcell.StyleDefault.
+            Foreground(tcell.ColorYellow).
+            Background(tcell.ColorDarkCyan).
+            Bold(true)).
+        SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+            pages.RemovePage("confirm")
+            journalPending = false
+            if buttonLabel == "Recover" {
+                n, err := replayJournal(recs)
+                if err != nil {
+                    showMessage("Journal recovery stopped after %d change(s): %v", n, err)
+                } else {
+                    showMessage("Recovered %d change(s)", n)
+                }
+            } else {
+                journalDiscard()
+            }
+            refreshTable()
+        })
+
+    pages.AddPage("confirm", modal, true, true)
+}
@@ -0,0 +1,888 @@
+/**
+ * MIT License
+ *
+ * Copyright (c) 2025 Viki (VN - initials of my first and last name)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ *
+ * Contact: contact@viki.design
+ * Website: https://www.viki.design
+ *
+ */
+
+package main
+
+/*
+  Keymap:
+
+    Keys run named actions ( move_left, undo, delete_row ... ), which key runs
+    which action is the keymap. It starts from a preset:
+
+        default       the keys csvgo always had ( see README )
+        vim           h j k l, g g / G, d d, y y, u, Ctrl+R ...
+        spreadsheet   F2 edits, Delete clears, Ctrl+C / Ctrl+V, Ctrl+Z ...
+
+    and is changed by keymap files, first the global one
+    $XDG_CONFIG_HOME/csvgo/keymap ( ~/.config/csvgo/keymap ), then the one of
+    the csv file, <name>.keymap next to it ( like <name>.config ):
+
+        # comments start with #
+        preset = vim
+        Ctrl+Z = undo
+        g g = first_row               # a chord: keys separated by spaces
+        n = search_next clear_cell    # the first action that applies runs
+        Backspace = none              # removes the binding
+
+    A key can not be bound twice in one file, and a key that starts a chord
+    can not be bound itself ( the chord could never be typed ). A keymap file
+    with an error is not used, the status bar tells why.
+
+    :set keymap=<preset> switches the preset, :map <keys> = <actions> and
+    :unmap <keys> change the keymap until csvgo quits.
+*/
+
+import (
+    "fmt"
+    "os"
+    "path/filepath"
+    "sort"
+    "strings"
+    "unicode/utf8"
+
+    "github.com/gdamore/tcell/v2"
+)
+
+// Something a key can do
+type action struct {
+    name string
+    help string
+
+    // false when the action does not apply ( search_next without a search ),
+    // the next action bound to the key is tried then
+    run func() bool
+}
+
+type keyMap struct {
+    preset string
+
+    // Chord ( key names separated by spaces ) -> names of the actions
+    bindings map[string][]string
+}
+
+// Bindings of a keymap file ( or preset ), in the order they were written
+type keymapSource struct {
+    name   string
+    preset string
+    lines  []keymapLine
+}
+
+type keymapLine struct {
+    line    int
+    chord   string
+    actions []string // none: remove the binding
+}
+
+var (
+    actions []*action
+
+    // Keymap of the current file, every tab has its own
+    keymap *keyMap
+
+    // Keys of a chord typed so far
+    pendingKeys []string
+
+    // Preset chosen with :set keymap=, used instead of the one of the files
+    keymapPreset string
+
+    // Errors of the keymap files last read
+    keymapErrors []error
+)
+
+var presets = map[string]string{
+    "default": `
+        Right = move_right
+        Left = move_left
+        Down = move_down
+        Up = move_up
+        Shift+Right = select_right
+        Shift+Left = select_left
+        Shift+Down = select_down
+        Shift+Up = select_up
+        V = select_rows
+        | = select_columns
+        Tab = insert_column
+        Enter = insert_row
+        Esc = clear_selection clear_search quit
+        Backspace = delete_column
+        Ctrl+Z = undo
+        Ctrl+Y = redo
+        Ctrl+S = save
+        Ctrl+F = search_hits
+        d = delete_row
+        e = edit
+        i = edit
+        E = edit_text
+        z = magnify
+        r = form
+        c = copy
+        v = paste
+        x = cut
+        S = save_as
+        : = command_line
+        q = quit
+        n = search_next clear_cell
+        Delete = clear_cell
+        N = search_previous
+        / = search
+        o = sort_ascending
+        O = sort_descending
+        R = replace
+        m = next_malformed
+        M = previous_malformed
+        f = filter
+        ] = next_tab
+        [ = previous_tab
+        y = yank_rows
+        p = put_rows
+        h = help
+        ? = help
+    `,
+    "vim": `
+        h = move_left
+        j = move_down
+        k = move_up
+        l = move_right
+        Left = move_left
+        Down = move_down
+        Up = move_up
+        Right = move_right
+        Shift+Left = select_left
+        Shift+Down = select_down
+        Shift+Up = select_up
+        Shift+Right = select_right
+        v = select_cells
+        V = select_rows
+        | = select_columns
+        g g = first_row
+        G = last_row
+        0 = first_column
+        $ = last_column
+        i = edit
+        a = edit
+        E = edit_text
+        z = magnify
+        g r = form
+        o = insert_row
+        Tab = insert_column
+        x = cut
+        D = clear_cell
+        d d = delete_row
+        d c = delete_column
+        y y = yank_rows
+        y c = copy
+        p = put_rows
+        P = paste
+        u = undo
+        Ctrl+R = redo
+        Ctrl+S = save
+        / = search
+        n = search_next
+        N = search_previous
+        g / = search_hits
+        : = command_line
+        s = sort_ascending
+        S = sort_descending
+        R = replace
+        f = filter
+        ] m = next_malformed
+        [ m = previous_malformed
+        g t = next_tab
+        g T = previous_tab
+        Esc = clear_selection clear_search
+        q = quit
+        ? = help
+        F1 = help
+    `,
+    "spreadsheet": `
+        Right = move_right
+        Left = move_left
+        Down = move_down
+        Up = move_up
+        Tab = move_right
+        Shift+Tab = move_left
+        Enter = move_down
+        Shift+Right = select_right
+        Shift+Left = select_left
+        Shift+Down = select_down
+        Shift+Up = select_up
+        Alt+R = select_rows
+        Alt+C = select_columns
+        Home = first_column
+        End = last_column
+        Ctrl+Home = first_row
+        Ctrl+End = last_row
+        F2 = edit
+        Shift+F2 = edit_text
+        F4 = magnify
+        Alt+E = form
+        Delete = clear_cell
+        Backspace = clear_cell
+        Insert = insert_row
+        Alt+Insert = insert_column
+        Ctrl+Delete = delete_row
+        Alt+Delete = delete_column
+        Ctrl+C = copy
+        Ctrl+V = paste
+        Ctrl+X = cut
+        Ctrl+Z = undo
+        Ctrl+Y = redo
+        Ctrl+S = save
+        F12 = save_as
+        Ctrl+F = search
+        F3 = search_next
+        Shift+F3 = search_previous
+        Alt+F = search_hits
+        Ctrl+H = replace
+        Ctrl+L = filter
+        Alt+A = sort_ascending
+        Alt+D = sort_descending
+        F8 = next_malformed
+        Shift+F8 = previous_malformed
+        Ctrl+PgDn = next_tab
+        Ctrl+PgUp = previous_tab
+        Alt+Y = yank_rows
+        Alt+P = put_rows
+        : = command_line
+        Esc = clear_selection clear_search
+        Ctrl+Q = quit
+        F1 = help
+        ? = help
+    `,
+}
+
+func registerAction(a *action) {
+    actions = append(actions, a)
+}
+
+func findAction(name string) *action {
+    for _, a := range actions {
+        if a.name == name {
+            return a
+        }
+    }
+    return nil
+}
+
+// An action that always applies
+func always(f func()) func() bool {
+    return func() bool {
+        f()
+        return true
+    }
+}
+
+func registerActions() {
+    registerAction(&action{name: "move_right", help: "Cursor one cell right", run: always(func() { moveCursor(0, 1, false) })})
+    registerAction(&action{name: "move_left", help: "Cursor one cell left", run: always(func() { moveCursor(0, -1, false) })})
+    registerAction(&action{name: "move_down", help: "Cursor one row down", run: always(func() { moveCursor(1, 0, false) })})
+    registerAction(&action{name: "move_up", help: "Cursor one row up", run: always(func() { moveCursor(-1, 0, false) })})
+    registerAction(&action{name: "select_right", help: "Grow the selection one cell right", run: always(func() { moveCursor(0, 1, true) })})
+    registerAction(&action{name: "select_left", help: "Grow the selection one cell left", run: always(func() { moveCursor(0, -1, true) })})
+    registerAction(&action{name: "select_down", help: "Grow the selection one row down", run: always(func() { moveCursor(1, 0, true) })})
+    registerAction(&action{name: "select_up", help: "Grow the selection one row up", run: always(func() { moveCursor(-1, 0, true) })})
+    registerAction(&action{name: "first_row", help: "Cursor to the header row", run: always(func() { jumpCursor(0, selectedCol) })})
+    registerAction(&action{name: "last_row", help: "Cursor to the last row", run: always(func() { jumpCursor(tableRowCount()-1, selectedCol) })})
+    registerAction(&action{name: "first_column", help: "Cursor to the first column", run: always(func() { jumpCursor(viewRowOf(selectedRow), 0) })})
+    registerAction(&action{name: "last_column", help: "Cursor to the last column", run: always(func() { jumpCursor(viewRowOf(selectedRow), numCols-1) })})
+
+    registerAction(&action{name: "select_rows", help: "Select whole rows, the cursor keys grow the selection", run: always(func() { toggleVisualSelection(selectRows) })})
+    registerAction(&action{name: "select_columns", help: "Select whole columns, the cursor keys grow the selection", run: always(func() { toggleVisualSelection(selectColumns) })})
+    registerAction(&action{name: "select_cells", help: "Select a block of cells, the cursor keys grow the selection", run: always(func() { toggleVisualSelection(selectCells) })})
+
+    registerAction(&action{name: "edit", help: "Edit the cell ( long cells and cells with line breaks in the text area )", run: always(startEditing)})
+    registerAction(&action{name: "magnify", help: "Show the cell on the whole screen, pretty printed, e edits", run: always(magnifyCell)})
+    registerAction(&action{name: "form", help: "Edit the row as a form, one labelled field per column", run: always(showRecordForm)})
+    registerAction(&action{name: "edit_text", help: "Edit the cell in the text area, Ctrl+Enter saves", run: always(startTextEditing)})
+    registerAction(&action{name: "clear_cell", help: "Empty the cell ( or the selection )", run: always(clearSelection)})
+    registerAction(&action{name: "copy", help: "Copy the cell ( or the selection, tab separated ) to the clipboard", run: always(func() { copySelection() })})
+    registerAction(&action{name: "paste", help: "Paste the clipboard at the cell, tab separated text as a block", run: always(pasteClipboard)})
+    registerAction(&action{name: "cut", help: "Copy the cell ( or the selection ) to the clipboard and empty it", run: always(cutSelection)})
+    registerAction(&action{name: "insert_row", help: "Insert a row below the cursor", run: always(insertRowBelow)})
+    registerAction(&action{name: "insert_column", help: "Insert a column right of the cursor", run: always(func() {
+        insertColumnRight()
+        refreshTable()
+    })})
+    registerAction(&action{name: "delete_row", help: "Delete the row ( or the selected rows, asks first )", run: always(func() {
+        // The row is copied to .completed.csv before it is deleted
+        if journalBusy() {
+            return
+        }
+        if rangeActive {
+            deleteSelectedRows()
+            return
+        }
+        getUserConfirmation("Do you want to delete selected row?", func() {
+            copySelectedRowToCompleted()
+            deleteSelectedRow()
+        })
+    })})
+    registerAction(&action{name: "delete_column", help: "Delete the column ( or the selected columns, asks first )", run: always(func() {
+        if rangeActive {
+            deleteSelectedColumns()
+            return
+        }
+        deleteColAfterConfirmation()
+    })})
+    registerAction(&action{name: "yank_rows", help: "Copy the row ( or the selected rows ) for put_rows", run: always(yankRows)})
+    registerAction(&action{name: "put_rows", help: "Insert the copied rows below the cursor", run: always(putRows)})
+    registerAction(&action{name: "undo", help: "Undo the last change", run: always(undo)})
+    registerAction(&action{name: "redo", help: "Redo the last undone change", run: always(redo)})
+
+    registerAction(&action{name: "save", help: "Write the file", run: always(func() { saveCSV(inputFile) })})
+    registerAction(&action{name: "save_as", help: "Write to another file", run: always(saveAs)})
+    registerAction(&action{name: "quit", help: "Close the file ( asks when there are unsaved changes )", run: always(confirmQuit)})
+    registerAction(&action{name: "command_line", help: "Open the command line", run: always(openCommandLine)})
+    registerAction(&action{name: "help", help: "Show the keys, commands and options", run: always(func() { showHelp("") })})
+
+    registerAction(&action{name: "search", help: "Search", run: always(openSearch)})
+    registerAction(&action{name: "search_next", help: "Next search hit", run: func() bool {
+        if searchActive {
+            searchNext(true)
+        }
+        return searchActive
+    }})
+    registerAction(&action{name: "search_previous", help: "Previous search hit", run: func() bool {
+        if searchActive {
+            searchNext(false)
+        }
+        return searchActive
+    }})
+    registerAction(&action{name: "search_hits", help: "List the search hits", run: func() bool {
+        if searchActive {
+            showSearchHits()
+        }
+        return searchActive
+    }})
+    registerAction(&action{name: "clear_search", help: "End the search", run: func() bool {
+        if !searchActive {
+            return false
+        }
+        clearSearch()
+        refreshTable()
+        return true
+    }})
+    registerAction(&action{name: "clear_selection", help: "End the range selection", run: func() bool {
+        if !rangeActive {
+            return false
+        }
+        clearRangeSelection()
+        refreshTable()
+        return true
+    }})
+    registerAction(&action{name: "replace", help: "Find and replace", run: always(openReplaceDialog)})
+    registerAction(&action{name: "filter", help: "Show only the rows that match", run: always(openFilter)})
+    registerAction(&action{name: "sort_ascending", help: "Sort by the column, ascending", run: always(func() { sortByCurrentColumn(false) })})
+    registerAction(&action{name: "sort_descending", help: "Sort by the column, descending", run: always(func() { sortByCurrentColumn(true) })})
+    registerAction(&action{name: "next_malformed", help: "Next malformed row", run: always(func() { jumpToMalformed(false) })})
+    registerAction(&action{name: "previous_malformed", help: "Previous malformed row", run: always(func() { jumpToMalformed(true) })})
+    registerAction(&action{name: "next_tab", help: "Next tab", run: always(func() { switchTab(currentTab + 1) })})
+    registerAction(&action{name: "previous_tab", help: "Previous tab", run: always(func() { switchTab(currentTab - 1) })})
+}
+
+// Run the actions bound to a key, false when the key is not bound ( the table
+// gets it then )
+func handleKey(event *tcell.EventKey) bool {
+    name := eventKeyName(event)
+    if name == "" || keymap == nil {
+        pendingKeys = nil
+        return false
+    }
+    chord := strings.Join(append(pendingKeys, name), " ")
+
+    if names, ok := keymap.bindings[chord]; ok {
+        pendingKeys = nil
+        runActions(names)
+        return true
+    }
+    if keymap.startsChord(chord) {
+        pendingKeys = append(pendingKeys, name)
+        showMessage("%s ...", chord)
+        return true
+    }
+    if len(pendingKeys) > 0 {
+        // The keys typed so far are dropped ( like vim ), Esc only cancels
+        pendingKeys = nil
+        if name != "Esc" {
+            showMessage("%s is not bound", chord)
+        }
+        return true
+    }
+    return false
+}
+
+func runActions(names []string) {
+    for _, name := range names {
+        if a := findAction(name); a != nil && a.run() {
+            return
+        }
+    }
+}
+
+// Names of the keys that are not characters, as written in keymap files
+var keyNames = map[tcell.Key]string{
+    tcell.KeyEnter:      "Enter",
+    tcell.KeyTab:        "Tab",
+    tcell.KeyBackspace:  "Backspace",
+    tcell.KeyBackspace2: "Backspace",
+    tcell.KeyEscape:     "Esc",
+    tcell.KeyDelete:     "Delete",
+    tcell.KeyInsert:     "Insert",
+    tcell.KeyHome:       "Home",
+    tcell.KeyEnd:        "End",
+    tcell.KeyPgUp:       "PgUp",
+    tcell.KeyPgDn:       "PgDn",
+    tcell.KeyUp:         "Up",
+    tcell.KeyDown:       "Down",
+    tcell.KeyLeft:       "Left",
+    tcell.KeyRight:      "Right",
+}
+
+// Other spellings accepted in keymap files
+var keyAliases = map[string]string{
+    "escape":   "Esc",
+    "return":   "Enter",
+    "bs":       "Backspace",
+    "del":      "Delete",
+    "ins":      "Insert",
+    "pageup":   "PgUp",
+    "pagedown": "PgDn",
+    "space":    "Space",
+}
+
+func modifierPrefix(mods tcell.ModMask) string {
+    prefix := ""
+    if mods&tcell.ModCtrl != 0 {
+        prefix += "Ctrl+"
+    }
+    if mods&(tcell.ModAlt|tcell.ModMeta) != 0 {
+        prefix += "Alt+"
+    }
+    if mods&tcell.ModShift != 0 {
+        prefix += "Shift+"
+    }
+    return prefix
+}
+
+// Name of a key press as written in keymap files ( "" for keys that can not
+// be bound )
+func eventKeyName(event *tcell.EventKey) string {
+    key, mods := event.Key(), event.Modifiers()
+    var name string
+    switch {
+    case key == tcell.KeyRune:
+        name = string(event.Rune())
+        if event.Rune() == ' ' {
+            name = "Space"
+        }
+        // Shift is part of the character already ( A, :, ? ... )
+        mods &^= tcell.ModShift
+        if mods&tcell.ModCtrl != 0 {
+            name = strings.ToUpper(name)
+        }
+    case key == tcell.KeyBacktab:
+        name, mods = "Tab", mods|tcell.ModShift
+    case key >= tcell.KeyF1 && key <= tcell.KeyF12:
+        name = fmt.Sprintf("F%d", key-tcell.KeyF1+1)
+    case keyNames[key] != "":
+        name = keyNames[key]
+    case key >= tcell.KeyCtrlA && key <= tcell.KeyCtrlZ:
+        name, mods = string(rune('A'+key-tcell.KeyCtrlA)), mods|tcell.ModCtrl
+    default:
+        return ""
+    }
+    return modifierPrefix(mods) + name
+}
+
+// Canonical name of a key written in a keymap file ( ctrl-z -> Ctrl+Z )
+func parseKey(s string) (string, error) {
+    var mods tcell.ModMask
+    rest := s
+    for {
+        // + and - are keys themselves, a modifier needs a key after it
+        i := strings.IndexAny(rest, "+-")
+        if i <= 0 || i == len(rest)-1 {
+            break
+        }
+        switch strings.ToLower(rest[:i]) {
+        case "ctrl", "c":
+            mods |= tcell.ModCtrl
+        case "alt", "meta", "a", "m":
+            mods |= tcell.ModAlt
+        case "shift", "s":
+            mods |= tcell.ModShift
+        default:
+            return "", fmt.Errorf("unknown modifier %q in %s", rest[:i], s)
+        }
+        rest = rest[i+1:]
+    }
+
+    if utf8.RuneCountInString(rest) == 1 {
+        name := rest
+        if mods&tcell.ModShift != 0 {
+            name = strings.ToUpper(name)
+            mods &^= tcell.ModShift
+        }
+        if mods&tcell.ModCtrl != 0 {
+            name = strings.ToUpper(name)
+            if name < "A" || name > "Z" {
+                return "", fmt.Errorf("%s can not be typed, Ctrl works with letters only", s)
+            }
+        }
+        return modifierPrefix(mods) + name, nil
+    }
+
+    name := keyAliases[strings.ToLower(rest)]
+    for _, known := range keyNames {
+        if strings.EqualFold(rest, known) {
+            name = known
+        }
+    }
+    var n int
+    if _, err := fmt.Sscanf(strings.ToUpper(rest), "F%d", &n); err == nil && n >= 1 && n <= 12 {
+        name = fmt.Sprintf("F%d", n)
+    }
+    if name == "" {
+        return "", fmt.Errorf("unknown key %q", rest)
+    }
+    return modifierPrefix(mods) + name, nil
+}
+
+// Canonical form of a chord, keys separated by spaces
+func parseChord(s string) (string, error) {
+    var keys []string
+    for _, field := range strings.Fields(s) {
+        key, err := parseKey(field)
+        if err != nil {
+            return "", err
+        }
+        keys = append(keys, key)
+    }
+    if len(keys) == 0 {
+        return "", fmt.Errorf("no key")
+    }
+    return strings.Join(keys, " "), nil
+}
+
+// Actions of a binding: names separated by spaces or commas, none for no actions
+func parseActions(s string) ([]string, error) {
+    names := strings.FieldsFunc(s, func(r rune) bool { return r == ' ' || r == ',' || r == '\t' })
+    if len(names) == 1 && names[0] == "none" {
+        return nil, nil
+    }
+    if len(names) == 0 {
+        return nil, fmt.Errorf("no action ( none removes a binding )")
+    }
+    for _, name := range names {
+        if findAction(name) == nil {
+            return nil, fmt.Errorf("unknown action %q", name)
+        }
+    }
+    return names, nil
+}
+
+// Parse a keymap file, every error found is returned
+func parseKeymap(name string, text string) (*keymapSource, []error) {
+    src := &keymapSource{name: name}
+    var errs []error
+    bound := make(map[string]int)
+
+    for i, line := range strings.Split(text, "\n") {
+        n := i + 1
+        // Comments also after a binding ( Ctrl+Z = undo  # like everywhere )
+        if c := strings.Index(line, " #"); c >= 0 {
+            line = line[:c]
+        }
+        line = strings.TrimSpace(line)
+        if line == "" || strings.HasPrefix(line, "#") {
+            continue
+        }
+        // The last =, so = can be bound itself ( = = undo )
+        eq := strings.LastIndex(line, "=")
+        if eq <= 0 {
+            errs = append(errs, fmt.Errorf("%s:%d: expected <keys> = <actions>", name, n))
+            continue
+        }
+        left, right := strings.TrimSpace(line[:eq]), strings.TrimSpace(line[eq+1:])
+
+        if left == "preset" {
+            if _, ok := presets[right]; !ok {
+                errs = append(errs, fmt.Errorf("%s:%d: unknown preset %q", name, n, right))
+                continue
+            }
+            src.preset = right
+            continue
+        }
+
+        chord, err := parseChord(left)
+        if err != nil {
+            errs = append(errs, fmt.Errorf("%s:%d: %v", name, n, err))
+            continue
+        }
+        names, err := parseActions(right)
+        if err != nil {
+            errs = append(errs, fmt.Errorf("%s:%d: %v", name, n, err))
+            continue
+        }
+        if first, ok := bound[chord]; ok {
+            errs = append(errs, fmt.Errorf("%s:%d: %s is bound on line %d already", name, n, chord, first))
+            continue
+        }
+        bound[chord] = n
+        src.lines = append(src.lines, keymapLine{line: n, chord: chord, actions: names})
+    }
+    return src, errs
+}
+
+// Bindings of a keymap file, nil when there is none
+func readKeymapFile(path string) (*keymapSource, []error) {
+    if path == "" {
+        return nil, nil
+    }
+    text, err := os.ReadFile(path)
+    if os.IsNotExist(err) {
+        return nil, nil
+    }
+    if err != nil {
+        return nil, []error{err}
+    }
+    return parseKeymap(path, string(text))
+}
+
+// Bound chords, sorted
+func (m *keyMap) chords() []string {
+    var chords []string
+    for chord := range m.bindings {
+        chords = append(chords, chord)
+    }
+    sort.Strings(chords)
+    return chords
+}
+
+func (m *keyMap) startsChord(keys string) bool {
+    for chord := range m.bindings {
+        if strings.HasPrefix(chord, keys+" ") {
+            return true
+        }
+    }
+    return false
+}
+
+func (m *keyMap) clone() *keyMap {
+    c := &keyMap{preset: m.preset, bindings: make(map[string][]string, len(m.bindings))}
+    for chord, names := range m.bindings {
+        c.bindings[chord] = names
+    }
+    return c
+}
+
+// Bind a chord ( no actions: remove the binding ), a chord and a key that
+// starts it can not both be bound
+func (m *keyMap) bind(chord string, names []string) error {
+    if len(names) == 0 {
+        delete(m.bindings, chord)
+        return nil
+    }
+    for _, other := range m.chords() {
+        if strings.HasPrefix(other, chord+" ") {
+            return fmt.Errorf("%s starts the chord %s ( bind %s = none first )", chord, other, other)
+        }
+        if strings.HasPrefix(chord, other+" ") {
+            return fmt.Errorf("%s is bound, the chord %s can not be typed ( bind %s = none first )", other, chord, other)
+        }
+    }
+    m.bindings[chord] = names
+    return nil
+}
+
+// Keymap with the bindings of src, m is not changed when one of them fails
+func (m *keyMap) apply(src *keymapSource) (*keyMap, error) {
+    c := m.clone()
+    for _, l := range src.lines {
+        if err := c.bind(l.chord, l.actions); err != nil {
+            return m, fmt.Errorf("%s:%d: %v", src.name, l.line, err)
+        }
+    }
+    return c, nil
+}
+
+// Preset ( the one of the last file naming one unless preset is set ) with
+// the keymap files applied, files with errors are left out
+func buildKeymap(preset string, paths []string) (*keyMap, []error) {
+    var sources []*keymapSource
+    var errs []error
+    for _, path := range paths {
+        src, srcErrs := readKeymapFile(path)
+        if len(srcErrs) > 0 {
+            errs = append(errs, srcErrs...)
+            continue
+        }
+        if src != nil {
+            sources = append(sources, src)
+        }
+    }
+
+    if preset == "" {
+        preset = "default"
+        for _, src := range sources {
+            if src.preset != "" {
+                preset = src.preset
+            }
+        }
+    }
+    m := &keyMap{preset: preset, bindings: make(map[string][]string)}
+    base, baseErrs := parseKeymap(preset+" preset", presets[preset])
+    errs = append(errs, baseErrs...)
+    m, err := m.apply(base)
+    if err != nil {
+        errs = append(errs, err)
+    }
+
+    for _, src := range sources {
+        if m, err = m.apply(src); err != nil {
+            errs = append(errs, err)
+        }
+    }
+    return m, errs
+}
+
+func globalKeymapPath() string {
+    dir := os.Getenv("XDG_CONFIG_HOME")
+    if dir == "" {
+        home, err := os.UserHomeDir()
+        if err != nil {
+            return ""
+        }
+        dir = filepath.Join(home, ".config")
+    }
+    return filepath.Join(dir, "csvgo", "keymap")
+}
+
+func getKeymapPath(csvPath string) string {
+    dir, file := filepath.Split(csvPath)
+    base := strings.TrimSuffix(file, filepath.Ext(file))
+    return filepath.Join(dir, base+".keymap")
+}
+
+// Keymap of the current file: the preset, the global keymap file and the one
+// of the file
+func loadKeymap() {
+    paths := []string{globalKeymapPath()}
+    if !readingStdin() {
+        paths = append(paths, getKeymapPath(inputFile))
+    }
+    keymap, keymapErrors = buildKeymap(keymapPreset, paths)
+    pendingKeys = nil
+
+    if len(keymapErrors) > 0 {
+        more := ""
+        if len(keymapErrors) > 1 {
+            more = fmt.Sprintf(" ( %d more errors )", len(keymapErrors)-1)
+        }
+        showMessage("Keymap not used: %v%s", keymapErrors[0], more)
+    }
+}
+
+// :map, :unmap and :set keymap
+func registerKeymapCommands() {
+    registerCommand(&command{
+        name: "map",
+        args: "[<keys> = <actions>]",
+        help: "Bind keys to actions until csvgo quits ( none removes the binding )",
+        run:  cmdMap,
+    })
+    registerCommand(&command{
+        name: "unmap",
+        args: "<keys>",
+        help: "Remove the binding of keys",
+        run: func(bang bool, args string) error {
+            chord, err := parseChord(args)
+            if err != nil {
+                return err
+            }
+            if _, ok := keymap.bindings[chord]; !ok {
+                return fmt.Errorf("%s is not bound", chord)
+            }
+            keymap.bind(chord, nil)
+            showMessage("%s unbound", chord)
+            return nil
+        },
+    })
+    registerOption(&option{
+        name: "keymap",
+        help: "Keymap preset: " + strings.Join(presetNames(), ", "),
+        get:  func() string { return keymap.preset },
+        set: func(value string) error {
+            if _, ok := presets[value]; !ok {
+                return fmt.Errorf("unknown keymap preset %q ( %s )", value, strings.Join(presetNames(), ", "))
+            }
+            keymapPreset = value
+            loadKeymap()
+            return nil
+        },
+    })
+}
+
+func presetNames() []string {
+    var names []string
+    for name := range presets {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+    return names
+}
+
+func cmdMap(bang bool, args string) error {
+    if strings.TrimSpace(args) == "" {
+        showMessage("Keymap %s: %d bindings", keymap.preset, len(keymap.bindings))
+        return nil
+    }
+    eq := strings.LastIndex(args, "=")
+    if eq <= 0 {
+        return fmt.Errorf("usage: :map <keys> = <actions>")
+    }
+    chord, err := parseChord(args[:eq])
+    if err != nil {
+        return err
+    }
+    names, err := parseActions(args[eq+1:])
+    if err != nil {
+        return err
+    }
+    if err := keymap.bind(chord, names); err != nil {
+        return err
+    }
+    if len(names) == 0 {
+        showMessage("%s unbound", chord)
+        return nil
+    }
+    showMessage("%s = %s", chord, strings.Join(names, " "))
+    return nil
+}
@@ -0,0 +1,152 @@
+/**
+ * MIT License
+ *
+ * Copyright (c) 2025 Viki (VN - initials of my first and last name)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ *
+ * Contact: contact@viki.design
+ * Website: https://www.viki.design
+ *
+ */
+
+package main
+import (
+    "fmt"
+    "reflect"
+    "testing"
+)
+
+// The actions the keymap files name, put back after the test
+func useTestActions(t *testing.T) {
+    old := actions
+    t.Cleanup(func() { actions = old })
+    actions = nil
+    registerActions()
+}
+
+func TestParseKey(t *testing.T) {
+    tests := []struct {
+        key, want string
+    }{
+        {"x", "x"},
+        {"X", "X"},
+        {"+", "+"},
+        {"-", "-"},
+        {"ctrl-z", "Ctrl+Z"},
+        {"C-z", "Ctrl+Z"},
+        {"Ctrl+Shift+z", "Ctrl+Z"},
+        {"shift+x", "X"},
+        {"alt-x", "Alt+x"},
+        {"meta+Enter", "Alt+Enter"},
+        {"ctrl++", ""},
+        {"shift+up", "Shift+Up"},
+        {"escape", "Esc"},
+        {"pagedown", "PgDn"},
+        {"space", "Space"},
+        {"f5", "F5"},
+        {"F13", ""},
+        {"ctrl-1", ""},
+        {"hyper-x", ""},
+        {"nokey", ""},
+    }
+    for _, tt := range tests {
+        got, err := parseKey(tt.key)
+        if got != tt.want || (err != nil) != (tt.want == "") {
+            t.Errorf("parseKey(%q) = %q, %v, want %q", tt.key, got, err, tt.want)
+        }
+    }
+}
+
+func TestParseKeymap(t *testing.T) {
+    useTestActions(t)
+    src, errs := parseKeymap("k", `# mine
+preset = vim
+ctrl-e = last_row  # comment
+g  x = undo, redo
+= = undo
+Q = none
+`)
+    if len(errs) > 0 {
+        t.Fatalf("parseKeymap: %v", errs)
+    }
+    want := []keymapLine{
+        {3, "Ctrl+E", []string{"last_row"}},
+        {4, "g x", []string{"undo", "redo"}},
+        {5, "=", []string{"undo"}},
+        {6, "Q", nil},
+    }
+    if src.preset != "vim" || !reflect.DeepEqual(src.lines, want) {
+        t.Errorf("parseKeymap = %q %+v, want vim %+v", src.preset, src.lines, want)
+    }
+
+    // Every line with an error is reported
+    _, errs = parseKeymap("k", `x undo
+preset = emacs
+ctrl-1 = undo
+y = nothing
+z =
+a = undo
+A = undo
+shift+a = redo
+`)
+    wantErrs := []string{
+        "k:1: expected <keys> = <actions>",
+        `k:2: unknown preset "emacs"`,
+        "k:3: ctrl-1 can not be typed, Ctrl works with letters only",
+        `k:4: unknown action "nothing"`,
+        "k:5: no action ( none removes a binding )",
+        "k:8: A is bound on line 7 already",
+    }
+    if got := fmt.Sprint(errs); got != fmt.Sprint(wantErrs) {
+        t.Errorf("parseKeymap errors\n%v\nwant\n%v", got, wantErrs)
+    }
+}
+
+func TestKeymapBind(t *testing.T) {
+    m := &keyMap{bindings: map[string][]string{"g g": {"first_row"}, "x": {"cut"}}}
+    tests := []struct {
+        chord string
+        want  string
+    }{
+        {"g", "g starts the chord g g ( bind g g = none first )"},
+        {"x y", "x is bound, the chord x y can not be typed ( bind x = none first )"},
+        {"g x", ""},
+        {"x", ""},
+    }
+    for _, tt := range tests {
+        err := m.bind(tt.chord, []string{"undo"})
+        if got := fmt.Sprint(err); tt.want != "" && got != tt.want || tt.want == "" && err != nil {
+            t.Errorf("bind(%q) = %v, want %q", tt.chord, err, tt.want)
+        }
+    }
+
+    // A file with a conflict changes nothing
+    src := &keymapSource{name: "k", lines: []keymapLine{{1, "y", []string{"undo"}}, {2, "g", []string{"undo"}}}}
+    got, err := m.apply(src)
+    if err == nil || err.Error() != "k:2: g starts the chord g g ( bind g g = none first )" || got != m || m.bindings["y"] != nil {
+        t.Errorf("apply of a conflict = %v, %v", got.bindings, err)
+    }
+    // none removes a binding
+    if got, err = m.apply(&keymapSource{lines: []keymapLine{{1, "x", nil}}}); err != nil || got.bindings["x"] != nil || m.bindings["x"] == nil {
+        t.Errorf("apply of x = none: %v, %v", got.bindings, err)
+    }
+}
@@ -0,0 +1,178 @@
+/**
+ * MIT License
+ *
+ * Copyright (c) 2025 Viki (VN - initials of my first and last name)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ *
+ * Contact: contact@viki.design
+ * Website: https://www.viki.design
+ *
+ */
+
+package main
+
+/*
+  Background loader:
+
+    loadCSV() only reads the header, the remaining rows are parsed in a
+    goroutine and handed to the UI goroutine in batches ( app.QueueUpdateDraw ),
+    so `data` is only ever touched by the UI goroutine. The first screen of rows
+    can be used while the rest of a big file is still loading; the status line
+    shows the progress.
+
+    While loading:
+      - rows can be edited, inserted and deleted ( new rows are appended at the end )
+      - columns can not be inserted / deleted ( rows still to come have the old layout )
+      - the file can not be saved
+    A leftover journal is offered for recovery once the whole file is loaded,
+    with one no change can be made while loading ( see journal.go ).
+*/
+
+import (
+    "io"
+    "os"
+    "sync/atomic"
+    "time"
+
+    "neoviki_spreadsheet/modules_neoviki/csvio"
+)
+
+const loadBatchRows = 10000
+const loadBatchInterval = 100 * time.Millisecond
+
+var (
+    loadFile   *os.File
+    loadReader *csvio.Reader
+
+    loading        bool
+    loadErr        error
+    loadTotalBytes int64
+    loadReadBytes  atomic.Int64
+
+    // Bumped for every file that is opened, batches of an older loader are dropped
+    loadGeneration atomic.Int64
+)
+
+// Counts the bytes read from the file for the progress indicator
+type countingReader struct {
+    r io.Reader
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+    n, err := cr.r.Read(p)
+    loadReadBytes.Add(int64(n))
+    return n, err
+}
+
+func startLoader() {
+    loading = true
+    loadErr = nil
+    journalSessionStart()
+    gen := loadGeneration.Add(1)
+    go loadRows(loadFile, loadReader, gen)
+}
+
+func loadRows(f *os.File, r *csvio.Reader, gen int64) {
+    defer f.Close()
+
+    var batch [][]string
+    var formats []*csvio.RowFormat
+    var issues []parseIssue
+    lastFlush := time.Now()
+
+    flush := func(done bool, err error) {
+        rows := batch
+        rowFormatsBatch := formats
+        issuesBatch := issues
+        batch = nil
+        formats = nil
+        issues = nil
+        lastFlush = time.Now()
+        tail := r.Tail()
+
+        app.QueueUpdateDraw(func() {
+            if gen != loadGeneration.Load() {
+                // Another file was opened in the meantime
+                return
+            }
+            // Rows with more fields than the header get columns of their own
+            doc.AppendRows(rows, rowFormatsBatch)
+            addParseIssues(issuesBatch)
+            // Keep the view where it is ( tview would follow the end of a growing table )
+            table.SetOffset(table.GetOffset())
+            if done {
+                if err == nil {
+                    // How the file ended is only known now
+                    doc.SetTail(tail)
+                }
+                loadDone(err)
+            }
+            updateStatusBar()
+        })
+    }
+
+    for gen == loadGeneration.Load() {
+        record, format, err := r.ReadRow()
+        if err == io.EOF {
+            flush(true, nil)
+            return
+        }
+        if err != nil {
+            // Malformed rows are kept, unless -strict
+            rowIssues := parseIssuesOf(record, format, err, r.FieldsPerRecord)
+            if rowIssues == nil {
+                flush(true, err)
+                return
+            }
+            issues = append(issues, rowIssues...)
+        }
+
+        batch = append(batch, record)
+        formats = append(formats, format)
+        if len(batch) >= loadBatchRows || time.Since(lastFlush) >= loadBatchInterval {
+            flush(false, nil)
+        }
+    }
+}
+
+// Runs on the UI goroutine once the whole file is read ( or reading failed )
+func loadDone(err error) {
+    loading = false
+    loadErr = err
+    if err != nil {
+        showMessage("Error reading csv file: %v ( file is read only )", err)
+        return
+    }
+    if len(parseIssues) > 0 {
+        showMessage("%d parse issue(s) ( m: next malformed row, :issues lists them, -strict to refuse such files )", len(parseIssues))
+    }
+    checkJournal()
+}
+
+// Column changes and saving have to wait for the loader
+func loadBusy() bool {
+    if loading {
+        showMessage("Still loading, try again when the file is loaded")
+        return true
+    }
+    return false
+}
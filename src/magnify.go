@@ -0,0 +1,322 @@
+/**
+ * MIT License
+ *
+ * Copyright (c) 2025 Viki (VN - initials of my first and last name)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ *
+ * Contact: contact@viki.design
+ * Website: https://www.viki.design
+ *
+ */
+
+package main
+
+/*
+  Cell magnifier:
+
+    z shows the selected cell on ( almost ) the whole screen. Long text wraps
+    and scrolls ( ↑ ↓ PgUp PgDn, Home / End ). JSON, XML and lists of URLs
+    are pretty printed: JSON and XML indented, one URL per line; p switches
+    between the pretty and the raw text.
+
+    e edits the text shown in the text area of editor.go, Ctrl+Enter writes
+    it back to the cell ( one undo step ) and shows it again. A pretty
+    printed cell that was one line in the file is made one line again
+    ( compact JSON / XML, the URLs joined like before ), so the csv file
+    keeps its shape.
+*/
+
+import (
+    "bytes"
+    "encoding/json"
+    "encoding/xml"
+    "fmt"
+    "io"
+    "net/url"
+    "strings"
+    "unicode"
+
+    "github.com/gdamore/tcell/v2"
+    "github.com/rivo/tview"
+)
+
+// z: magnify the selected cell
+func magnifyCell() {
+    if selectedRow >= len(data) || selectedCol >= numCols {
+        return
+    }
+    magnify_cell_full_screen(app, selectedRow, selectedCol, table, pages)
+}
+
+func magnify_cell_full_screen(app *tview.Application, row int, col int, table *tview.Table, pages *tview.Pages) {
+    textView := tview.NewTextView()
+    textView.SetScrollable(true).
+        SetWrap(true).
+        SetWordWrap(true).
+        SetTextColor(tcell.ColorWhite).
+        SetBackgroundColor(tcell.ColorBlack).
+        SetBorder(true)
+
+    // The view and the text area take turns in the middle of the page
+    inner := tview.NewFlex()
+    show := func(p tview.Primitive) {
+        inner.Clear()
+        inner.AddItem(nil, 2, 0, false).      // left padding: 2 cols
+            AddItem(p, 0, 1, true).           // main content
+            AddItem(nil, 2, 0, false)         // right padding: 2 cols
+        app.SetFocus(p)
+    }
+    closeMagnifier := func() {
+        pages.RemovePage("magnify")
+        app.SetFocus(table)
+    }
+
+    content := cellText(row, col)
+    format := detectFormat(content)
+    pretty := format != ""
+    shown := func() string {
+        if pretty {
+            return prettyText(format, content)
+        }
+        return content
+    }
+    refresh := func() {
+        mode := ""
+        if format != "" {
+            mode = fmt.Sprintf(", %s ( p: raw )", format)
+            if !pretty {
+                mode = fmt.Sprintf(", raw ( p: %s )", format)
+            }
+        }
+        textView.SetTitle(fmt.Sprintf(" Row %d, %s%s  e: edit, Esc: close ", row, tview.Escape(strings.TrimSpace(wrapText(cellText(0, col), 20))), mode))
+        textView.SetText(shown())
+        textView.ScrollToBeginning()
+    }
+    refresh()
+
+    edit := func() {
+        if headerReadOnly(row) {
+            return
+        }
+        before := shown()
+        title := fmt.Sprintf(" Edit row %d, %s ( Ctrl+Enter: save, Esc: back ) ", row, tview.Escape(strings.TrimSpace(wrapText(cellText(0, col), 20))))
+        editor := newCellTextArea(before, title, func(text string) {
+            if text != before {
+                if pretty {
+                    text = compactText(format, text, content)
+                }
+                setCell(row, col, text)
+                content = cellText(row, col)
+                format = detectFormat(content)
+                pretty = pretty && format != ""
+            }
+            refresh()
+            show(textView)
+        }, func() {
+            show(textView)
+        })
+        show(editor)
+    }
+
+    textView.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+        if event.Key() == tcell.KeyEscape {
+            closeMagnifier()
+            return nil
+        }
+        switch event.Rune() {
+        case 'p':
+            if format != "" {
+                pretty = !pretty
+                refresh()
+            }
+            return nil
+        case 'e':
+            edit()
+            return nil
+        case 'q':
+            closeMagnifier()
+            return nil
+        }
+        return event
+    })
+
+    modal := tview.NewFlex().
+        SetDirection(tview.FlexRow).
+        AddItem(nil, 1, 0, false). // top padding: 1 row
+        AddItem(inner, 0, 1, true).
+        AddItem(nil, 1, 0, false) // bottom padding: 1 row
+
+    pages.AddPage("magnify", modal, true, true)
+    show(textView)
+}
+
+// "JSON", "XML", "URLs" or "" for text that is shown as it is
+func detectFormat(text string) string {
+    trimmed := strings.TrimSpace(text)
+    switch {
+    case trimmed == "":
+        return ""
+    case (trimmed[0] == '{' || trimmed[0] == '[') && json.Valid([]byte(trimmed)):
+        return "JSON"
+    case trimmed[0] == '<':
+        if _, ok := reencodeXML(trimmed, "  "); ok {
+            return "XML"
+        }
+    }
+    if urls, _ := splitURLs(trimmed); len(urls) > 1 {
+        return "URLs"
+    }
+    return ""
+}
+
+func prettyText(format string, text string) string {
+    text = strings.TrimSpace(text)
+    switch format {
+    case "JSON":
+        var b bytes.Buffer
+        if json.Indent(&b, []byte(text), "", "  ") == nil {
+            return b.String()
+        }
+    case "XML":
+        if pretty, ok := reencodeXML(text, "  "); ok {
+            return pretty
+        }
+    case "URLs":
+        urls, _ := splitURLs(text)
+        return strings.Join(urls, "\n")
+    }
+    return text
+}
+
+// Edited pretty text back in the shape of the cell: one line when the cell
+// was one line, as it is when it can not be read any more
+func compactText(format string, text string, original string) string {
+    if strings.ContainsAny(original, "\r\n") {
+        return text
+    }
+    switch format {
+    case "JSON":
+        var b bytes.Buffer
+        if json.Compact(&b, []byte(text)) == nil {
+            return b.String()
+        }
+    case "XML":
+        if compact, ok := reencodeXML(text, ""); ok {
+            return compact
+        }
+    case "URLs":
+        _, sep := splitURLs(original)
+        return strings.Join(strings.FieldsFunc(text, isURLSeparator), sep)
+    }
+    return text
+}
+
+// XML written again with indent ( "": on one line ), the blanks between
+// elements are dropped. Every token is copied from text as it is ( prefixes,
+// quotes, entities, CDATA ), only the line breaks and indents are new.
+func reencodeXML(text string, indent string) (string, bool) {
+    dec := xml.NewDecoder(strings.NewReader(text))
+    var b strings.Builder
+    var open []xml.Name
+    elements := 0
+    // Written last: a start tag ( its end tag stays on the line ) or text (
+    // a line break after it would become part of it )
+    afterStart, afterText := false, false
+
+    newline := func() {
+        if indent != "" && b.Len() > 0 && !afterText {
+            b.WriteString("\n" + strings.Repeat(indent, len(open)))
+        }
+    }
+    for {
+        start := dec.InputOffset()
+        tok, err := dec.RawToken()
+        if err == io.EOF {
+            break
+        }
+        if err != nil {
+            return "", false
+        }
+        raw := text[start:dec.InputOffset()]
+
+        switch t := tok.(type) {
+        case xml.StartElement:
+            elements++
+            newline()
+            b.WriteString(raw)
+            open = append(open, t.Name)
+            afterStart, afterText = true, false
+        case xml.EndElement:
+            if len(open) == 0 || open[len(open)-1] != t.Name {
+                return "", false
+            }
+            open = open[:len(open)-1]
+            // raw is empty for the end of <a/>
+            if raw != "" {
+                if !afterStart {
+                    newline()
+                }
+                b.WriteString(raw)
+            }
+            afterStart, afterText = false, false
+        case xml.CharData:
+            if strings.TrimSpace(string(t)) == "" {
+                continue
+            }
+            b.WriteString(raw)
+            afterStart, afterText = false, true
+        default:
+            // Comments, <?xml ...?>, <!DOCTYPE ...>
+            newline()
+            b.WriteString(raw)
+            afterStart, afterText = false, false
+        }
+    }
+    if len(open) > 0 || elements == 0 {
+        return "", false
+    }
+    return b.String(), true
+}
+
+func isURLSeparator(r rune) bool {
+    return unicode.IsSpace(r) || r == ',' || r == ';' || r == '|'
+}
+
+// The URLs of a list and the text between the first two, nil when one of the
+// words is not a URL
+func splitURLs(text string) ([]string, string) {
+    urls := strings.FieldsFunc(text, isURLSeparator)
+    for _, s := range urls {
+        u, err := url.Parse(s)
+        if err != nil || u.Scheme == "" || u.Host == "" {
+            return nil, ""
+        }
+    }
+    sep := " "
+    if len(urls) > 1 {
+        i := strings.Index(text, urls[0]) + len(urls[0])
+        if j := strings.Index(text[i:], urls[1]); j >= 0 {
+            sep = text[i : i+j]
+        }
+    }
+    return urls, sep
+}
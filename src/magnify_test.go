@@ -0,0 +1,76 @@
+/**
+ * MIT License
+ *
+ * Copyright (c) 2025 Viki (VN - initials of my first and last name)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ *
+ * Contact: contact@viki.design
+ * Website: https://www.viki.design
+ *
+ */
+
+package main
+import "testing"
+
+func TestReencodeXML(t *testing.T) {
+    soap := `<soap:Envelope xmlns:soap="http://x"><soap:Body><m:Get xmlns:m='urn:m' m:id="1"/></soap:Body></soap:Envelope>`
+    tests := []struct {
+        text   string
+        pretty string // "": not XML
+    }{
+        {`<a><b x="1">text</b></a>`, "<a>\n  <b x=\"1\">text</b>\n</a>"},
+        // Prefixes, quotes and empty elements stay as they are
+        {soap, "<soap:Envelope xmlns:soap=\"http://x\">\n  <soap:Body>\n    <m:Get xmlns:m='urn:m' m:id=\"1\"/>\n  </soap:Body>\n</soap:Envelope>"},
+        {"<?xml version=\"1.0\"?>\n<a>\n  <!-- note -->\n  <b>x &amp; y</b>\n  <c><![CDATA[<raw>]]></c>\n</a>\n",
+            "<?xml version=\"1.0\"?>\n<a>\n  <!-- note -->\n  <b>x &amp; y</b>\n  <c><![CDATA[<raw>]]></c>\n</a>"},
+        // No line breaks in mixed content, they would change the text
+        {"<a>one <b>two</b></a>", "<a>one <b>two</b>\n</a>"},
+        {"<a><b>two</b> three</a>", "<a>\n  <b>two</b> three</a>"},
+        {"<a></a>", "<a></a>"},
+        {"<a><b></a>", ""},
+        {"<a>", ""},
+        {"<a></b>", ""},
+        {"<p:a></q:a>", ""},
+        {"plain text", ""},
+        {"<", ""},
+    }
+    for _, tt := range tests {
+        pretty, ok := reencodeXML(tt.text, "  ")
+        if ok != (tt.pretty != "") || pretty != tt.pretty {
+            t.Errorf("reencodeXML(%q) = %q, %v, want %q", tt.text, pretty, ok, tt.pretty)
+            continue
+        }
+        if !ok {
+            continue
+        }
+        // Pretty and compact again: the text without the blanks between elements
+        compact, ok := reencodeXML(pretty, "")
+        want, _ := reencodeXML(tt.text, "")
+        if !ok || compact != want {
+            t.Errorf("compact of %q = %q, %v, want %q", pretty, compact, ok, want)
+        }
+    }
+
+    if compact, _ := reencodeXML(soap, ""); compact != soap {
+        t.Errorf("compact of %q = %q", soap, compact)
+    }
+}
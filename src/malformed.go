@@ -0,0 +1,286 @@
+/**
+ * MIT License
+ *
+ * Copyright (c) 2025 Viki (VN - initials of my first and last name)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ *
+ * Contact: contact@viki.design
+ * Website: https://www.viki.design
+ *
+ */
+
+package main
+
+/*
+  Malformed rows:
+
+    Files are read leniently ( unless -strict ): rows with too few or too many
+    fields and bad quoting do not stop the load. Every problem is kept with
+    its line number ( :issues lists them ). Malformed rows are shown in red,
+    the missing / extra cells of a row are marked, m / M jump to the next /
+    previous one. A row counts as malformed while
+
+      - it has another number of fields than the header, or
+      - it had a quoting problem and was not edited since ( an edited row is
+        written with proper quoting )
+
+    Rows with more fields than the header get columns of their own ( with an
+    empty header cell ). Unchanged rows are saved as they were read, so a
+    malformed file is not "repaired" behind the user's back; :pad fills short
+    rows up to the header, :pad! all rows up to the widest one.
+*/
+
+import (
+    "errors"
+    "fmt"
+
+    "github.com/gdamore/tcell/v2"
+    "github.com/rivo/tview"
+
+    "neoviki_spreadsheet/modules_neoviki/csvio"
+)
+
+var (
+    // -strict: stop at the first malformed row ( the file is read only then )
+    strictParse bool
+
+    // Every problem found while reading the file, in file order
+    parseIssues []parseIssue
+
+    // Rows with a quoting problem, by their format ( which moves with the row )
+    quoteIssues map[*csvio.RowFormat]bool
+)
+
+type parseIssue struct {
+    line   int
+    format *csvio.RowFormat
+    text   string
+
+    // A quoting problem ( otherwise the number of fields is wrong )
+    quoting bool
+}
+
+// Issues of a row the reader returned together with err, nil if err is not
+// a problem a lenient read can live with. fields is the header length.
+func parseIssuesOf(record []string, format *csvio.RowFormat, err error, fields int) []parseIssue {
+    var pe *csvio.ParseError
+    if err == nil || record == nil || !errors.As(err, &pe) {
+        return nil
+    }
+
+    var issues []parseIssue
+    if pe.Err != csvio.ErrFieldCount {
+        issues = append(issues, parseIssue{line: pe.Line, format: format, text: fmt.Sprintf("column %d: %v", pe.Column, pe.Err), quoting: true})
+    }
+    if len(record) != fields {
+        issues = append(issues, parseIssue{line: pe.StartLine, format: format, text: fmt.Sprintf("%d fields, the header has %d", len(record), fields)})
+    }
+    return issues
+}
+
+func addParseIssues(issues []parseIssue) {
+    for _, issue := range issues {
+        if issue.quoting {
+            quoteIssues[issue.format] = true
+        }
+    }
+    parseIssues = append(parseIssues, issues...)
+}
+
+// Why row is malformed, "" if it is not
+func malformedReason(row int) string {
+    if row < 0 || row >= len(data) {
+        return ""
+    }
+    if n := len(data[row]); row > 0 && n != len(data[0]) {
+        return fmt.Sprintf("%d fields, the header has %d", n, len(data[0]))
+    }
+    if f := rowFormats[row]; f != nil && f.Text != "" && quoteIssues[f] {
+        return "bad quoting"
+    }
+    return ""
+}
+
+// Same as malformedReason != "", without building the text ( used for every cell on screen )
+func rowMalformed(row int) bool {
+    if row > 0 && len(data[row]) != len(data[0]) {
+        return true
+    }
+    f := rowFormats[row]
+    return f != nil && f.Text != "" && quoteIssues[f]
+}
+
+// Cells a row is missing or has too many
+func cellMisplaced(row int, col int) bool {
+    return row > 0 && (col >= len(data[row]) || col >= len(data[0]))
+}
+
+func malformedRowCount() int {
+    n := 0
+    for r := range data {
+        if rowMalformed(r) {
+            n++
+        }
+    }
+    return n
+}
+
+// Move to the next ( or previous ) visible malformed row, wrapping around
+func jumpToMalformed(backward bool) {
+    step := 1
+    if backward {
+        step = -1
+    }
+    for i := 1; i <= len(data); i++ {
+        r := ((selectedRow+step*i)%len(data) + len(data)) % len(data)
+        if !rowVisible(r) || !rowMalformed(r) {
+            continue
+        }
+        selectedRow = r
+        refreshTable()
+        showMessage("Row %d: %s ( %d malformed row(s) )", r, malformedReason(r), malformedRowCount())
+        return
+    }
+    showMessage("No malformed rows")
+}
+
+// List of every parse issue, Enter jumps to the row
+func showParseIssues() {
+    list := tview.NewList().ShowSecondaryText(false)
+    list.SetBorder(true)
+
+    rowOf := make(map[*csvio.RowFormat]int, len(parseIssues))
+    for r, f := range rowFormats {
+        if f != nil {
+            rowOf[f] = r
+        }
+    }
+
+    rows := make([]int, len(parseIssues))
+    for i, issue := range parseIssues {
+        row, ok := rowOf[issue.format]
+        state := ""
+        switch {
+        case !ok:
+            row = -1
+            state = " ( row deleted )"
+        case !rowMalformed(row):
+            state = " ( fixed )"
+        }
+        rows[i] = row
+
+        rowText := "-"
+        if row >= 0 {
+            rowText = fmt.Sprint(row)
+        }
+        list.AddItem(fmt.Sprintf("line %-6d row %-6s %s%s", issue.line, rowText, tview.Escape(issue.text), state), "", 0, nil)
+    }
+    list.SetTitle(fmt.Sprintf(" %d parse issue(s), %d malformed row(s) now ( Enter: jump, Esc: close ) ", len(parseIssues), malformedRowCount()))
+
+    closeIssues := func() {
+        pages.RemovePage("issues")
+        app.SetFocus(table)
+    }
+
+    list.SetSelectedFunc(func(index int, mainText string, secondaryText string, shortcut rune) {
+        closeIssues()
+        if rows[index] < 0 {
+            return
+        }
+        selectedRow = rows[index]
+        if !rowVisible(selectedRow) {
+            showMessage("Row %d is hidden by the filter", selectedRow)
+        }
+        refreshTable()
+    })
+    list.SetDoneFunc(closeIssues)
+
+    modal := tview.NewFlex().
+        SetDirection(tview.FlexRow).
+        AddItem(nil, 2, 0, false).
+        AddItem(
+            tview.NewFlex().
+                AddItem(nil, 4, 0, false).
+                AddItem(list, 0, 1, true).
+                AddItem(nil, 4, 0, false),
+            0, 1, true).
+        AddItem(nil, 2, 0, false)
+
+    pages.AddPage("issues", modal, true, true)
+    app.SetFocus(list)
+}
+
+// Fill short rows with empty cells up to width, as one undo step
+func padRows(width int) int {
+    var ops batchOp
+    for r := range data {
+        // Column labels are not data ( see header.go )
+        if len(data[r]) >= width || r == 0 && !doc.HasHeader() {
+            continue
+        }
+        cells := make([]string, width)
+        copy(cells, data[r])
+        ops = append(ops, &setRowOp{row: r, oldCells: data[r], newCells: cells})
+    }
+    if len(ops) > 0 && !runOp(ops, selectedRow, selectedCol) {
+        return 0
+    }
+    return len(ops)
+}
+
+func registerMalformedCommands() {
+    registerCommand(&command{
+        name: "issues",
+        help: "List the problems found while reading the file",
+        run: func(bang bool, args string) error {
+            if len(parseIssues) == 0 {
+                showMessage("No parse issues")
+                return nil
+            }
+            showParseIssues()
+            return nil
+        },
+    })
+    registerCommand(&command{
+        name: "pad",
+        help: "Fill short rows with empty cells up to the header ( ! up to the widest row )",
+        run: func(bang bool, args string) error {
+            if loadBusy() {
+                return nil
+            }
+            width := len(data[0])
+            if bang {
+                width = numCols
+            }
+            showMessage("Padded %d row(s) to %d fields", padRows(width), width)
+            return nil
+        },
+    })
+}
+
+// Malformed rows in red, the cells they miss or have too many on a dark background
+func styleMalformedCell(cell *tview.TableCell, row int, col int) {
+    cell.SetTextColor(tcell.ColorRed)
+    if cellMisplaced(row, col) {
+        cell.SetBackgroundColor(tcell.ColorMaroon)
+    }
+}
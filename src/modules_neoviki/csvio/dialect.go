@@ -0,0 +1,322 @@
+/**
+ * MIT License
+ *
+ * Copyright (c) 2025 Viki (VN - initials of my first and last name)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ *
+ * Contact: contact@viki.design
+ * Website: https://www.viki.design
+ *
+ */
+
+// Package csvio reads and writes csv files in different dialects
+// ( delimiter, quote character, line endings ... ) and guesses the dialect
+// of a file. Rows that were not changed can be written back byte for byte
+// ( see RowFormat ).
+package csvio
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Dialect describes how a csv file is written
+type Dialect struct {
+	Delimiter rune
+	Quote     rune
+	HasHeader bool
+
+	// Line ending of new and changed rows: "\n" or "\r\n"
+	LineEnding string
+
+	// File starts with a UTF-8 byte order mark
+	BOM bool
+
+	// Every field is quoted, not only those that need it
+	QuoteAll bool
+
+	// What follows the last row: its line ending and blank lines, "" for a
+	// file without a final newline ( see Reader.Tail )
+	Tail string
+
+	// Character encoding of the file, "" or UTF8 for UTF-8 ( see encoding.go )
+	Encoding string
+}
+
+// DefaultDialect is RFC 4180 with a header row and "\n" line endings
+var DefaultDialect = Dialect{Delimiter: ',', Quote: '"', HasHeader: true, LineEnding: "\n", Tail: "\n"}
+
+var delimiterNames = map[string]rune{
+	"comma":     ',',
+	"semicolon": ';',
+	"tab":       '\t',
+	"pipe":      '|',
+	"colon":     ':',
+	"space":     ' ',
+}
+
+// ParseDelimiter accepts a single character, `\t` or a name like "tab" or "semicolon"
+func ParseDelimiter(s string) (rune, error) {
+	if r, ok := delimiterNames[strings.ToLower(s)]; ok {
+		return r, nil
+	}
+	if s == `\t` {
+		return '\t', nil
+	}
+	return parseChar(s, "delimiter")
+}
+
+// ParseQuote accepts a single character ( " or ' )
+func ParseQuote(s string) (rune, error) {
+	switch strings.ToLower(s) {
+	case "double":
+		return '"', nil
+	case "single":
+		return '\'', nil
+	}
+	return parseChar(s, "quote")
+}
+
+func parseChar(s string, what string) (rune, error) {
+	r, size := utf8.DecodeRuneInString(s)
+	if s == "" || size != len(s) || r == utf8.RuneError {
+		return 0, fmt.Errorf("bad %s %q ( expected a single character )", what, s)
+	}
+	if r == '\r' || r == '\n' {
+		return 0, fmt.Errorf("bad %s %q", what, s)
+	}
+	return r, nil
+}
+
+// DelimiterName is the readable form of a delimiter ( "tab" instead of a tab )
+func DelimiterName(r rune) string {
+	for name, d := range delimiterNames {
+		if d == r && (r == '\t' || r == ' ') {
+			return name
+		}
+	}
+	return string(r)
+}
+
+func (d Dialect) Validate() error {
+	if d.Delimiter == d.Quote {
+		return fmt.Errorf("delimiter and quote character are the same ( %q )", d.Delimiter)
+	}
+	if d.Delimiter == 0 || d.Quote == 0 {
+		return fmt.Errorf("delimiter and quote character must be set")
+	}
+	return nil
+}
+
+func (d Dialect) String() string {
+	header := "yes"
+	if !d.HasHeader {
+		header = "no"
+	}
+	s := fmt.Sprintf("delimiter=%s quote=%s header=%s", DelimiterName(d.Delimiter), string(d.Quote), header)
+	if d.Encoding != "" && d.Encoding != UTF8 {
+		s += " encoding=" + d.Encoding
+	}
+	if d.LineEnding == "\r\n" {
+		s += " crlf"
+	}
+	if d.BOM {
+		s += " bom"
+	}
+	if d.QuoteAll {
+		s += " quote-all"
+	}
+	if d.Tail == "" {
+		s += " no-final-newline"
+	}
+	return s
+}
+
+// Sniffing
+
+// SniffSize is how much of a file Sniff looks at
+const SniffSize = 64 * 1024
+
+// Max number of records used to decide
+const sniffRecords = 100
+
+var sniffDelimiters = []rune{',', ';', '\t', '|', ':'}
+var sniffQuotes = []rune{'"', '\''}
+
+// Sniff guesses the dialect from the start of a file. The delimiter that
+// splits the most lines into the same number of fields wins, then the quote
+// character that makes that split the most consistent. The header is detected
+// by comparing the first row with the rows below ( see hasHeader ). Line
+// endings, BOM and quoting are taken from the sample as well.
+// DefaultDialect is returned when nothing fits better.
+func Sniff(sample []byte) Dialect {
+	bom := bytes.HasPrefix(sample, utf8BOM)
+	sample = bytes.TrimPrefix(sample, utf8BOM)
+
+	lineEnding := "\n"
+	if crlf := bytes.Count(sample, []byte("\r\n")); crlf > 0 && crlf*2 >= bytes.Count(sample, []byte("\n")) {
+		lineEnding = "\r\n"
+	}
+
+	// A cut off last line would have a wrong number of fields
+	if i := bytes.LastIndexByte(sample, '\n'); i >= 0 && i < len(sample)-1 {
+		sample = sample[:i+1]
+	}
+
+	best := DefaultDialect
+	bestScore := 0.0
+	bestFields := 0
+	var bestRecords [][]string
+
+	for _, quote := range sniffQuotes {
+		for _, delim := range sniffDelimiters {
+			d := Dialect{Delimiter: delim, Quote: quote}
+			records := sniffParse(sample, d)
+			score, fields := consistency(records)
+			if fields < 2 {
+				continue
+			}
+			if score > bestScore || (score == bestScore && fields > bestFields) {
+				best, bestScore, bestFields, bestRecords = d, score, fields, records
+			}
+		}
+	}
+
+	best.HasHeader = true
+	if bestRecords != nil {
+		best.HasHeader = hasHeader(bestRecords)
+	}
+	best.QuoteAll = quoteAll(sample, best)
+	best.BOM = bom
+	best.LineEnding = lineEnding
+	best.Tail = lineEnding
+	return best
+}
+
+// Every field of the sample is quoted
+func quoteAll(sample []byte, d Dialect) bool {
+	r := NewReader(bytes.NewReader(sample), d)
+	r.FieldsPerRecord = -1
+	rows := 0
+	for ; rows < sniffRecords; rows++ {
+		rec, f, err := r.ReadRow()
+		if err != nil {
+			break
+		}
+		if f.Quoted == nil {
+			return false
+		}
+		for i := range rec {
+			if !f.Quoted[i] {
+				return false
+			}
+		}
+	}
+	return rows > 0
+}
+
+func sniffParse(sample []byte, d Dialect) [][]string {
+	r := NewReader(bytes.NewReader(sample), d)
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+
+	var records [][]string
+	for len(records) < sniffRecords {
+		rec, err := r.Read()
+		if err != nil {
+			break
+		}
+		records = append(records, rec)
+	}
+	return records
+}
+
+// Share of records that have the most common number of fields, and that number
+func consistency(records [][]string) (float64, int) {
+	if len(records) == 0 {
+		return 0, 0
+	}
+	counts := make(map[int]int)
+	mode := 0
+	for _, rec := range records {
+		counts[len(rec)]++
+		if counts[len(rec)] > counts[mode] || (counts[len(rec)] == counts[mode] && len(rec) > mode) {
+			mode = len(rec)
+		}
+	}
+	return float64(counts[mode]) / float64(len(records)), mode
+}
+
+// A column says "header" when all of its values below the first row are
+// numbers ( or have the same length ) and the first row's value is not / has
+// not. Without such columns the first row is taken as a header.
+func hasHeader(records [][]string) bool {
+	if len(records) < 2 {
+		return true
+	}
+	header := records[0]
+	body := records[1:]
+
+	votes := 0
+	for c, name := range header {
+		numeric, sameLength, length, values := true, true, -1, 0
+		for _, rec := range body {
+			if c >= len(rec) || strings.TrimSpace(rec[c]) == "" {
+				continue
+			}
+			v := strings.TrimSpace(rec[c])
+			values++
+			if _, err := strconv.ParseFloat(v, 64); err != nil {
+				numeric = false
+			}
+			n := utf8.RuneCountInString(v)
+			if length == -1 {
+				length = n
+			} else if n != length {
+				sameLength = false
+			}
+		}
+		if values == 0 {
+			continue
+		}
+
+		name = strings.TrimSpace(name)
+		switch {
+		case numeric:
+			if _, err := strconv.ParseFloat(name, 64); err == nil {
+				votes--
+			} else {
+				votes++
+			}
+		case sameLength:
+			if utf8.RuneCountInString(name) == length {
+				votes--
+			} else {
+				votes++
+			}
+		}
+	}
+	return votes >= 0
+}
@@ -0,0 +1,150 @@
+/**
+ * MIT License
+ *
+ * Copyright (c) 2025 Viki (VN - initials of my first and last name)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ *
+ * Contact: contact@viki.design
+ * Website: https://www.viki.design
+ *
+ */
+package csvio
+
+import "testing"
+
+func TestSniff(t *testing.T) {
+	tests := []struct {
+		name      string
+		sample    string
+		delimiter rune
+		quote     rune
+		header    bool
+	}{
+		{"comma", "name,age\nann,30\nbob,40\n", ',', '"', true},
+		{"tab", "name\tage\nann\t30\nbob\t40\n", '\t', '"', true},
+		// Decimal commas do not make it comma separated
+		{"semicolon", "name;price\napple;1,5\npear;2,25\n", ';', '"', true},
+		{"pipe", "name|age|city\nann|30|Rome\nbob|40|Oslo\n", '|', '"', true},
+		{"double quotes", "name,note\n\"ann\",\"a, b\"\n\"bob\",\"c, d\"\n", ',', '"', true},
+		{"single quotes", "name,note\n'ann','a, b'\n'bob','c, d'\n", ',', '\'', true},
+		{"quoted delimiters", "a;b\n\"1;2\";3\n\"4;5\";6\n", ';', '"', true},
+		// Nothing splits the lines: the default dialect
+		{"one column", "name\nann\nbob\n", ',', '"', true},
+		{"empty", "", ',', '"', true},
+		{"cut off last line", "a,b\n1,2\n3,4\n5", ',', '"', true},
+	}
+	for _, tt := range tests {
+		d := Sniff([]byte(tt.sample))
+		if d.Delimiter != tt.delimiter || d.Quote != tt.quote || d.HasHeader != tt.header {
+			t.Errorf("%s: Sniff = delimiter %q quote %q header %v, want %q %q %v", tt.name, d.Delimiter, d.Quote, d.HasHeader, tt.delimiter, tt.quote, tt.header)
+		}
+	}
+}
+
+func TestSniffHeader(t *testing.T) {
+	tests := []struct {
+		sample string
+		header bool
+	}{
+		{"id,value\n1,2\n3,4\n", true},
+		{"1,2\n3,4\n5,6\n", false},
+		// Codes of the same length below a longer name
+		{"code,amount\nAB12,x\nCD34,y\n", true},
+		{"AB12,xy\nCD34,zw\nEF56,uv\n", false},
+		// Text of different lengths says nothing, the first row is the header
+		{"name,city\nann,rome\nbarbara,stockholm\n", true},
+		// One row: a header without data
+		{"a,b\n", true},
+		// Empty cells below do not vote
+		{"name,age\nann,\nbob,40\n", true},
+	}
+	for _, tt := range tests {
+		if d := Sniff([]byte(tt.sample)); d.HasHeader != tt.header {
+			t.Errorf("Sniff(%q).HasHeader = %v, want %v", tt.sample, d.HasHeader, tt.header)
+		}
+	}
+}
+
+func TestSniffLayout(t *testing.T) {
+	tests := []struct {
+		sample     string
+		lineEnding string
+		bom        bool
+		quoteAll   bool
+	}{
+		{"a,b\n1,2\n", "\n", false, false},
+		{"a,b\r\n1,2\r\n", "\r\n", false, false},
+		// The line ending most of the lines have
+		{"a,b\r\n1,2\r\n3,4\n", "\r\n", false, false},
+		{"a,b\n1,2\n3,4\r\n", "\n", false, false},
+		{"\xEF\xBB\xBFa,b\n1,2\n", "\n", true, false},
+		{"\"a\",\"b\"\n\"1\",\"2\"\n", "\n", false, true},
+		{"\"a\",b\n\"1\",\"2\"\n", "\n", false, false},
+	}
+	for _, tt := range tests {
+		d := Sniff([]byte(tt.sample))
+		if d.LineEnding != tt.lineEnding || d.Tail != tt.lineEnding || d.BOM != tt.bom || d.QuoteAll != tt.quoteAll {
+			t.Errorf("Sniff(%q) = line ending %q tail %q bom %v quote-all %v, want %q %v %v", tt.sample, d.LineEnding, d.Tail, d.BOM, d.QuoteAll, tt.lineEnding, tt.bom, tt.quoteAll)
+		}
+	}
+}
+
+func TestParseDelimiter(t *testing.T) {
+	tests := []struct {
+		in   string
+		want rune
+		ok   bool
+	}{
+		{",", ',', true},
+		{"tab", '\t', true},
+		{`\t`, '\t', true},
+		{"Semicolon", ';', true},
+		{"pipe", '|', true},
+		{"|", '|', true},
+		{"", 0, false},
+		{";;", 0, false},
+		{"\n", 0, false},
+	}
+	for _, tt := range tests {
+		got, err := ParseDelimiter(tt.in)
+		if got != tt.want || (err == nil) != tt.ok {
+			t.Errorf("ParseDelimiter(%q) = %q, %v", tt.in, got, err)
+		}
+	}
+}
+
+func TestDialectValidate(t *testing.T) {
+	tests := []struct {
+		d  Dialect
+		ok bool
+	}{
+		{DefaultDialect, true},
+		{Dialect{Delimiter: ';', Quote: '\''}, true},
+		{Dialect{Delimiter: '"', Quote: '"'}, false},
+		{Dialect{Delimiter: ','}, false},
+	}
+	for _, tt := range tests {
+		if err := tt.d.Validate(); (err == nil) != tt.ok {
+			t.Errorf("%v: Validate() = %v", tt.d, err)
+		}
+	}
+}
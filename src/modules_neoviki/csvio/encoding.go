@@ -0,0 +1,190 @@
+/**
+ * MIT License
+ *
+ * Copyright (c) 2025 Viki (VN - initials of my first and last name)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ *
+ * Contact: contact@viki.design
+ * Website: https://www.viki.design
+ *
+ */
+
+package csvio
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+// Encoding names, Dialect.Encoding is one of these ( "" is UTF-8 )
+const (
+	UTF8        = "utf-8"
+	UTF16LE     = "utf-16le"
+	UTF16BE     = "utf-16be"
+	Latin1      = "latin-1"
+	Windows1252 = "windows-1252"
+)
+
+var encodingAliases = map[string]string{
+	"utf8":         UTF8,
+	"utf-8":        UTF8,
+	"utf16":        UTF16LE,
+	"utf-16":       UTF16LE,
+	"utf16le":      UTF16LE,
+	"utf-16le":     UTF16LE,
+	"utf16be":      UTF16BE,
+	"utf-16be":     UTF16BE,
+	"latin1":       Latin1,
+	"latin-1":      Latin1,
+	"iso-8859-1":   Latin1,
+	"iso8859-1":    Latin1,
+	"windows1252":  Windows1252,
+	"windows-1252": Windows1252,
+	"cp1252":       Windows1252,
+}
+
+// ParseEncoding returns the canonical name of an encoding
+func ParseEncoding(name string) (string, error) {
+	if e, ok := encodingAliases[strings.ToLower(strings.TrimSpace(name))]; ok {
+		return e, nil
+	}
+	return "", fmt.Errorf("unknown encoding %q ( utf-8, utf-16le, utf-16be, latin-1, windows-1252 )", name)
+}
+
+// A BOM is not handled by the UTF-16 codecs: it is decoded to U+FEFF and
+// handled like a UTF-8 BOM ( see Reader.BOM, Dialect.BOM )
+func lookupEncoding(name string) encoding.Encoding {
+	switch name {
+	case UTF16LE:
+		return unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM)
+	case UTF16BE:
+		return unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM)
+	case Latin1:
+		return charmap.ISO8859_1
+	case Windows1252:
+		return charmap.Windows1252
+	}
+	return nil
+}
+
+// DetectEncoding guesses the encoding from the start of a file: a BOM, zero
+// bytes in every other position ( UTF-16 without BOM ), valid UTF-8, and
+// otherwise Windows-1252 if it uses the bytes 0x80 - 0x9F ( control
+// characters in Latin-1 ) or Latin-1 if not.
+func DetectEncoding(sample []byte) string {
+	switch {
+	case bytes.HasPrefix(sample, utf8BOM):
+		return UTF8
+	case bytes.HasPrefix(sample, []byte{0xFF, 0xFE}):
+		return UTF16LE
+	case bytes.HasPrefix(sample, []byte{0xFE, 0xFF}):
+		return UTF16BE
+	}
+
+	if len(sample) >= 4 {
+		n := min(len(sample), 4096) &^ 1
+		evenZeros, oddZeros := 0, 0
+		for i := 0; i < n; i += 2 {
+			if sample[i] == 0 {
+				evenZeros++
+			}
+			if sample[i+1] == 0 {
+				oddZeros++
+			}
+		}
+		// Mostly ASCII text: one byte of every pair is zero
+		switch {
+		case oddZeros > n/4 && evenZeros <= n/40:
+			return UTF16LE
+		case evenZeros > n/4 && oddZeros <= n/40:
+			return UTF16BE
+		}
+	}
+
+	// The sample may end in the middle of a character
+	end := len(sample)
+	for i := 0; i < utf8.UTFMax && end > 0 && !utf8.RuneStart(sample[end-1]); i++ {
+		end--
+	}
+	if end > 0 && !utf8.FullRune(sample[end-1:]) {
+		end--
+	}
+	if utf8.Valid(sample[:end]) {
+		return UTF8
+	}
+
+	for _, b := range sample {
+		if b >= 0x80 && b <= 0x9F {
+			return Windows1252
+		}
+	}
+	return Latin1
+}
+
+// NewDecoder returns a reader that turns text in the named encoding into UTF-8
+func NewDecoder(r io.Reader, name string) io.Reader {
+	enc := lookupEncoding(name)
+	if enc == nil {
+		return r
+	}
+	return transform.NewReader(r, enc.NewDecoder())
+}
+
+// Representable reports whether s can be written in the named encoding
+func Representable(name string, s string) bool {
+	enc := lookupEncoding(name)
+	if enc == nil {
+		return true
+	}
+	_, err := enc.NewEncoder().String(s)
+	return err == nil
+}
+
+// s with every character enc can not store replaced by "?"
+func replaceUnsupported(enc *encoding.Encoder, s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if _, err := enc.String(string(r)); err != nil {
+			r = '?'
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// Writer for UTF-8 text in another encoding, nil for UTF-8. The Writer
+// replaces what the encoding can not store before it gets here.
+func newEncodingWriter(w io.Writer, name string) *transform.Writer {
+	enc := lookupEncoding(name)
+	if enc == nil {
+		return nil
+	}
+	return transform.NewWriter(w, encoding.ReplaceUnsupported(enc.NewEncoder()))
+}
@@ -0,0 +1,226 @@
+/**
+ * MIT License
+ *
+ * Copyright (c) 2025 Viki (VN - initials of my first and last name)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ *
+ * Contact: contact@viki.design
+ * Website: https://www.viki.design
+ *
+ */
+package csvio
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+// s as UTF-16 bytes
+func utf16Bytes(s string, bigEndian bool) []byte {
+	var b []byte
+	for _, r := range s {
+		if bigEndian {
+			b = append(b, byte(r>>8), byte(r))
+		} else {
+			b = append(b, byte(r), byte(r>>8))
+		}
+	}
+	return b
+}
+
+func TestDetectEncoding(t *testing.T) {
+	text := "name,city\nann,Zürich\n"
+	tests := []struct {
+		name   string
+		sample []byte
+		want   string
+	}{
+		{"utf-16le bom", utf16Bytes("\uFEFF"+text, false), UTF16LE},
+		{"utf-16be bom", utf16Bytes("\uFEFF"+text, true), UTF16BE},
+		{"utf-16le", utf16Bytes(text, false), UTF16LE},
+		{"utf-16be", utf16Bytes(text, true), UTF16BE},
+		// Odd length, the last pair is incomplete
+		{"utf-16le cut", utf16Bytes(text, false)[:9], UTF16LE},
+		{"utf-8 bom", []byte("\xEF\xBB\xBF" + text), UTF8},
+		{"utf-8", []byte(text), UTF8},
+		{"ascii", []byte("a,b\n1,2\n"), UTF8},
+		{"empty", nil, UTF8},
+		// Cut in the middle of "ü" or "€"
+		{"utf-8 cut", []byte("ann,Z\xC3"), UTF8},
+		{"utf-8 cut 3", []byte("price,\xE2\x82"), UTF8},
+		// Bytes 0xA0 - 0xFF only: Latin-1
+		{"latin-1", []byte("ann,Z\xFCrich\nbob,\xA3 5\n"), Latin1},
+		// 0x80 ( € ) and 0x93 0x94 ( quotes ) are control characters in Latin-1
+		{"windows-1252 euro", []byte("price,\x80 5\n"), Windows1252},
+		{"windows-1252 quotes", []byte("ann,\x93hi\x94,Z\xFCrich\n"), Windows1252},
+		{"windows-1252 9f", []byte("a,\x9F\n"), Windows1252},
+		// A byte that is invalid UTF-8 in the middle decides
+		{"latin-1 not cut", []byte("Z\xFCrich,x"), Latin1},
+	}
+	for _, tt := range tests {
+		if got := DetectEncoding(tt.sample); got != tt.want {
+			t.Errorf("DetectEncoding(%s %q) = %q, want %q", tt.name, tt.sample, got, tt.want)
+		}
+	}
+}
+
+func TestParseEncoding(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"UTF8", UTF8},
+		{" utf-16 ", UTF16LE},
+		{"UTF-16BE", UTF16BE},
+		{"ISO-8859-1", Latin1},
+		{"cp1252", Windows1252},
+	}
+	for _, tt := range tests {
+		if got, err := ParseEncoding(tt.name); got != tt.want || err != nil {
+			t.Errorf("ParseEncoding(%q) = %q, %v, want %q", tt.name, got, err, tt.want)
+		}
+	}
+	if _, err := ParseEncoding("ebcdic"); err == nil {
+		t.Errorf("ParseEncoding(%q) gave no error", "ebcdic")
+	}
+}
+
+// The bytes 0x80 - 0x9F decode differently in Latin-1 and Windows-1252
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		encoding string
+		in       string
+		want     string
+	}{
+		{Latin1, "Z\xFCrich \x80", "Zürich \u0080"},
+		{Windows1252, "Z\xFCrich \x80 \x93x\x94", "Zürich € “x”"},
+		{UTF16LE, string(utf16Bytes("\uFEFFa,€\n", false)), "\uFEFFa,€\n"},
+		{UTF16BE, string(utf16Bytes("a,€\n", true)), "a,€\n"},
+		{UTF8, "a,€\n", "a,€\n"},
+	}
+	for _, tt := range tests {
+		b, err := io.ReadAll(NewDecoder(strings.NewReader(tt.in), tt.encoding))
+		if err != nil || string(b) != tt.want {
+			t.Errorf("decode %q from %s = %q, %v, want %q", tt.in, tt.encoding, b, err, tt.want)
+		}
+	}
+}
+
+func TestRepresentable(t *testing.T) {
+	tests := []struct {
+		encoding string
+		s        string
+		want     bool
+	}{
+		{Latin1, "Zürich", true},
+		{Latin1, "€", false},
+		{Windows1252, "€ “x”", true},
+		{Windows1252, "\u0080", false},
+		{Windows1252, "日本", false},
+		{UTF16LE, "日本", true},
+		{UTF8, "日本", true},
+	}
+	for _, tt := range tests {
+		if got := Representable(tt.encoding, tt.s); got != tt.want {
+			t.Errorf("Representable(%s, %q) = %v, want %v", tt.encoding, tt.s, got, tt.want)
+		}
+	}
+}
+
+// Characters the encoding can not store are written as "?", Lost counts the
+// fields and keeps the first one
+func TestWriteLossy(t *testing.T) {
+	tests := []struct {
+		encoding string
+		rows     [][]string
+		want     string
+		lost     int
+		row, col int
+	}{
+		{Latin1, [][]string{{"city", "price"}, {"Zürich", "5 €"}, {"日本", "x"}}, "city,price\nZ\xFCrich,5 ?\n??,x\n", 2, 1, 1},
+		{Windows1252, [][]string{{"a", "b"}, {"5 €", "日本"}}, "a,b\n5 \x80,??\n", 1, 1, 1},
+		// A replaced field that needs quotes still gets them
+		{Latin1, [][]string{{"€,x"}}, "\"?,x\"\n", 1, 0, 0},
+		{Windows1252, [][]string{{"Zürich", "“x”"}}, "Z\xFCrich,\x93x\x94\n", 0, 0, 0},
+		{UTF16LE, [][]string{{"日本"}}, string(utf16Bytes("日本\n", false)), 0, 0, 0},
+		{UTF16BE, [][]string{{"a", "€"}}, string(utf16Bytes("a,€\n", true)), 0, 0, 0},
+	}
+	for _, tt := range tests {
+		d := DefaultDialect
+		d.Encoding = tt.encoding
+		var b bytes.Buffer
+		w := NewWriter(&b, d)
+		if err := w.WriteAll(tt.rows); err != nil {
+			t.Fatalf("WriteAll: %v", err)
+		}
+		if b.String() != tt.want {
+			t.Errorf("%q in %s wrote %q, want %q", tt.rows, tt.encoding, b.String(), tt.want)
+		}
+		if n, row, col := w.Lost(); n != tt.lost || row != tt.row || col != tt.col {
+			t.Errorf("%q in %s: Lost() = %d, %d, %d, want %d, %d, %d", tt.rows, tt.encoding, n, row, col, tt.lost, tt.row, tt.col)
+		}
+	}
+}
+
+// Unchanged rows are checked as well ( e.g. after :set encoding=latin-1 )
+func TestWriteLossyUnchanged(t *testing.T) {
+	rows, formats, r := readRows(t, "city,price\r\nZürich,\"5 €\"\r\n日本,1\r\nOslo,2\r\n", DefaultDialect)
+	d := DefaultDialect
+	d.Encoding, d.Tail = Latin1, r.Tail()
+	var b bytes.Buffer
+	w := NewWriter(&b, d)
+	for i, row := range rows {
+		if err := w.WriteRow(row, formats[i]); err != nil {
+			t.Fatalf("WriteRow: %v", err)
+		}
+	}
+	if err := w.Finish(); err != nil {
+		t.Fatalf("Finish: %v", err)
+	}
+	// Quotes and line endings stay
+	if want := "city,price\r\nZ\xFCrich,\"5 ?\"\r\n??,1\r\nOslo,2\r\n"; b.String() != want {
+		t.Errorf("wrote %q, want %q", b.String(), want)
+	}
+	if n, row, col := w.Lost(); n != 2 || row != 1 || col != 1 {
+		t.Errorf("Lost() = %d, %d, %d, want 2, 1, 1", n, row, col)
+	}
+}
+
+// The BOM of a UTF-16 file is written in its encoding
+func TestWriteBOMUTF16(t *testing.T) {
+	for _, bigEndian := range []bool{false, true} {
+		d := DefaultDialect
+		d.Encoding, d.BOM = UTF16LE, true
+		if bigEndian {
+			d.Encoding = UTF16BE
+		}
+		var b bytes.Buffer
+		if err := NewWriter(&b, d).WriteAll([][]string{{"a", "b"}}); err != nil {
+			t.Fatalf("WriteAll: %v", err)
+		}
+		if want := string(utf16Bytes("\uFEFFa,b\n", bigEndian)); b.String() != want {
+			t.Errorf("%s with BOM wrote %q, want %q", d.Encoding, b.String(), want)
+		}
+	}
+}
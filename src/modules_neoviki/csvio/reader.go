@@ -0,0 +1,456 @@
+/**
+ * MIT License
+ *
+ * Copyright (c) 2025 Viki (VN - initials of my first and last name)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ *
+ * Contact: contact@viki.design
+ * Website: https://www.viki.design
+ *
+ */
+
+package csvio
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// Same errors as encoding/csv
+var (
+	ErrBareQuote  = errors.New("bare quote in non-quoted-field")
+	ErrQuote      = errors.New("extraneous or missing quote in quoted-field")
+	ErrFieldCount = errors.New("wrong number of fields")
+)
+
+// ParseError tells where a record could not be read ( lines start at 1 )
+type ParseError struct {
+	StartLine int
+	Line      int
+	Column    int
+	Err       error
+}
+
+func (e *ParseError) Error() string {
+	if e.Err == ErrFieldCount {
+		return fmt.Sprintf("record on line %d: %v", e.Line, e.Err)
+	}
+	if e.StartLine != e.Line {
+		return fmt.Sprintf("record on line %d; parse error on line %d, column %d: %v", e.StartLine, e.Line, e.Column, e.Err)
+	}
+	return fmt.Sprintf("parse error on line %d, column %d: %v", e.Line, e.Column, e.Err)
+}
+
+func (e *ParseError) Unwrap() error { return e.Err }
+
+// RowFormat is how a row was written in the file, so an unchanged row can be
+// written back byte for byte and a changed one close to how it was
+type RowFormat struct {
+	// Blank lines before the row
+	Prefix string
+
+	// The row as it was in the file without its line ending, "" once the
+	// row was changed ( it is written from its fields then )
+	Text string
+
+	// "\n", "\r\n" or "" for the last line of a file without a final newline
+	Ending string
+
+	// Fields that were quoted, nil if none was
+	Quoted []bool
+}
+
+// Reader works like encoding/csv.Reader, with the delimiter and quote
+// character taken from a Dialect. Empty lines are skipped. A UTF-8 BOM at
+// the start is skipped as well ( see BOM ).
+type Reader struct {
+	Dialect Dialect
+
+	// 0: every record has as many fields as the first one, > 0: exactly
+	// that many, < 0: any number
+	FieldsPerRecord int
+
+	// Quotes inside unquoted fields and stray quotes in quoted fields are kept
+	LazyQuotes bool
+
+	// Bad quoting does not stop reading: the record is read as well as
+	// possible ( like LazyQuotes, but a quote that is never closed only
+	// quotes the rest of its line ) and returned together with a
+	// *ParseError. Records with the wrong number of fields are returned
+	// with their *ParseError as well.
+	Lenient bool
+
+	r    *bufio.Reader
+	line int
+
+	// Lines read ahead that belong to the next records ( see unreadLines )
+	unread []string
+
+	// Raw lines of the record being read
+	recordLines []string
+
+	// First problem of a lenient read, and from where on ( line, byte )
+	// quotes do not open fields anymore
+	issue       *ParseError
+	noQuoteLine int
+	noQuoteFrom int
+
+	bom     bool
+	started bool
+
+	// Raw text of the record being read and of the blank lines before it
+	raw        strings.Builder
+	lastEnding string
+	tail       string
+}
+
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+func NewReader(r io.Reader, d Dialect) *Reader {
+	br, ok := r.(*bufio.Reader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+	return &Reader{Dialect: d, r: br}
+}
+
+// Line of the last line read
+func (r *Reader) Line() int {
+	return r.line
+}
+
+// BOM reports whether the input started with a UTF-8 byte order mark
+func (r *Reader) BOM() bool {
+	return r.bom
+}
+
+// Tail is what followed the last record: its line ending and blank lines
+// ( "" if the input did not end with a newline ). Valid after io.EOF.
+func (r *Reader) Tail() string {
+	return r.tail
+}
+
+// Next line with a "\r\n" ending turned into "\n", io.EOF after the last
+// one. The line as it was read is added to r.raw.
+func (r *Reader) readLine() (string, error) {
+	if !r.started {
+		r.started = true
+		if b, err := r.r.Peek(len(utf8BOM)); err == nil && bytes.Equal(b, utf8BOM) {
+			r.r.Discard(len(utf8BOM))
+			r.bom = true
+		}
+	}
+
+	var line string
+	var err error
+	if n := len(r.unread); n > 0 {
+		line = r.unread[n-1]
+		r.unread = r.unread[:n-1]
+	} else {
+		line, err = r.r.ReadString('\n')
+	}
+	if len(line) > 0 {
+		r.line++
+		r.raw.WriteString(line)
+		r.recordLines = append(r.recordLines, line)
+		if err == io.EOF {
+			err = nil
+		}
+		if strings.HasSuffix(line, "\r\n") {
+			line = line[:len(line)-2] + "\n"
+		}
+	}
+	return line, err
+}
+
+func (r *Reader) Read() ([]string, error) {
+	record, _, err := r.ReadRow()
+	return record, err
+}
+
+// ReadRow reads a record together with how it was written
+func (r *Reader) ReadRow() ([]string, *RowFormat, error) {
+	var line string
+	var err error
+
+	r.raw.Reset()
+	r.recordLines = r.recordLines[:0]
+	for {
+		line, err = r.readLine()
+		if err == io.EOF {
+			r.tail = r.lastEnding + r.raw.String()
+			return nil, nil, err
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if line != "\n" {
+			break
+		}
+	}
+
+	// Blank lines before the record
+	raw := r.raw.String()
+	prefix := raw[:len(raw)-len(r.rawLastLine(line))]
+	r.raw.Reset()
+	r.raw.WriteString(raw[len(prefix):])
+	r.recordLines = append(r.recordLines[:0], raw[len(prefix):])
+	startLine := r.line
+
+	r.issue = nil
+	r.noQuoteLine = 0
+	record, quoted, err := r.parseRecord(line)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	text := r.raw.String()
+	f := &RowFormat{Prefix: prefix, Quoted: quoted}
+	switch {
+	case strings.HasSuffix(text, "\r\n"):
+		f.Ending = "\r\n"
+	case strings.HasSuffix(text, "\n"):
+		f.Ending = "\n"
+	}
+	f.Text = text[:len(text)-len(f.Ending)]
+
+	r.lastEnding = f.Ending
+
+	if r.issue != nil {
+		return record, f, r.issue
+	}
+	if r.FieldsPerRecord > 0 && len(record) != r.FieldsPerRecord {
+		return record, f, &ParseError{StartLine: startLine, Line: startLine, Column: 1, Err: ErrFieldCount}
+	}
+	if r.FieldsPerRecord == 0 {
+		r.FieldsPerRecord = len(record)
+	}
+	return record, f, nil
+}
+
+// Lenient: note the first problem of the record, true if reading goes on
+func (r *Reader) problem(e *ParseError) bool {
+	if !r.Lenient {
+		return false
+	}
+	if r.issue == nil {
+		r.issue = e
+	}
+	return true
+}
+
+// Give back all lines of the record but the first one, they are read again
+// for the next records
+func (r *Reader) unreadLines() {
+	lines := r.recordLines[1:]
+	for i := len(lines) - 1; i >= 0; i-- {
+		r.unread = append(r.unread, lines[i])
+	}
+	r.line -= len(lines)
+	r.recordLines = r.recordLines[:1]
+	r.raw.Reset()
+	r.raw.WriteString(r.recordLines[0])
+}
+
+// Quotes at or after the noQuote position are plain characters
+func (r *Reader) quoteOpens(pos int) bool {
+	return r.noQuoteLine == 0 || r.line < r.noQuoteLine || r.line == r.noQuoteLine && pos < r.noQuoteFrom
+}
+
+// Raw form of the normalized line that was read last
+func (r *Reader) rawLastLine(line string) string {
+	raw := r.raw.String()
+	if strings.HasSuffix(raw, "\r\n") && strings.HasSuffix(line, "\n") {
+		return raw[len(raw)-len(line)-1:]
+	}
+	return raw[len(raw)-len(line):]
+}
+
+func (r *Reader) parseRecord(line string) ([]string, []bool, error) {
+	delim, quote := r.Dialect.Delimiter, r.Dialect.Quote
+	delimLen := utf8.RuneLen(delim)
+	quoteLen := utf8.RuneLen(quote)
+	startLine := r.line
+
+	var record []string
+	var quoted []bool
+	markQuoted := func() {
+		if quoted == nil {
+			quoted = make([]bool, len(record)-1, len(record)+4)
+		}
+		for len(quoted) < len(record)-1 {
+			quoted = append(quoted, false)
+		}
+		quoted = append(quoted, true)
+	}
+	done := func() ([]string, []bool, error) {
+		for quoted != nil && len(quoted) < len(record) {
+			quoted = append(quoted, false)
+		}
+		return record, quoted, nil
+	}
+
+	pos := 0 // byte offset of line in the current physical line, for errors
+
+	for {
+		if !strings.HasPrefix(line, string(quote)) || !r.quoteOpens(pos) {
+			// Unquoted field, up to the delimiter or the end of the line
+			i := strings.IndexRune(line, delim)
+			field := line
+			if i >= 0 {
+				field = line[:i]
+			} else {
+				field = strings.TrimSuffix(field, "\n")
+			}
+			if !r.LazyQuotes {
+				if j := strings.IndexRune(field, quote); j >= 0 {
+					col := utf8.RuneCountInString(line[:j]) + pos + 1
+					e := &ParseError{StartLine: startLine, Line: r.line, Column: col, Err: ErrBareQuote}
+					if !r.problem(e) {
+						return nil, nil, e
+					}
+				}
+			}
+			record = append(record, field)
+			if i < 0 {
+				return done()
+			}
+			line = line[i+delimLen:]
+			pos += i + delimLen
+			continue
+		}
+
+		// Quoted field, may go on over several lines
+		var sb strings.Builder
+		fieldLine, fieldStart := r.line, pos
+		line = line[quoteLen:]
+		pos += quoteLen
+
+		// Lenient: the quote was not meant to open a field, read the record
+		// again with it as a plain character
+		reparse := func(e *ParseError) ([]string, []bool, error) {
+			e.Line, e.Column = fieldLine, fieldStart+1
+			r.problem(e)
+			r.unreadLines()
+			r.noQuoteLine, r.noQuoteFrom = fieldLine, fieldStart
+			first := r.recordLines[0]
+			if strings.HasSuffix(first, "\r\n") {
+				first = first[:len(first)-2] + "\n"
+			}
+			return r.parseRecord(first)
+		}
+		for {
+			i := strings.IndexRune(line, quote)
+			if i < 0 {
+				sb.WriteString(line)
+				next, err := r.readLine()
+				if err != nil {
+					e := &ParseError{StartLine: startLine, Line: r.line, Column: pos + len(line) + 1, Err: ErrQuote}
+					if r.Lenient && r.line > fieldLine {
+						// The quote is never closed
+						return reparse(e)
+					}
+					if !r.LazyQuotes && !r.problem(e) {
+						return nil, nil, e
+					}
+					// Field runs to the end of the file
+					record = append(record, strings.TrimSuffix(sb.String(), "\n"))
+					markQuoted()
+					return done()
+				}
+				line = next
+				pos = 0
+				continue
+			}
+
+			sb.WriteString(line[:i])
+			line = line[i+quoteLen:]
+			pos += i + quoteLen
+
+			switch {
+			case strings.HasPrefix(line, string(quote)):
+				// Doubled quote
+				sb.WriteRune(quote)
+				line = line[quoteLen:]
+				pos += quoteLen
+				continue
+			case strings.HasPrefix(line, string(delim)):
+				record = append(record, sb.String())
+				markQuoted()
+				line = line[delimLen:]
+				pos += delimLen
+			case line == "\n" || line == "":
+				record = append(record, sb.String())
+				markQuoted()
+				return done()
+			case r.LazyQuotes:
+				sb.WriteRune(quote)
+				continue
+			default:
+				e := &ParseError{StartLine: startLine, Line: r.line, Column: pos + 1, Err: ErrQuote}
+				if r.Lenient && r.line > fieldLine {
+					// A field over several lines that does not end with its
+					// quote: most likely a stray quote took the lines after it
+					return reparse(e)
+				}
+				if !r.problem(e) {
+					return nil, nil, e
+				}
+				// Text after the closing quote is part of the field, up to
+				// the delimiter ( as spreadsheets read it )
+				j := strings.IndexRune(line, delim)
+				if j < 0 {
+					sb.WriteString(strings.TrimSuffix(line, "\n"))
+					record = append(record, sb.String())
+					markQuoted()
+					return done()
+				}
+				sb.WriteString(line[:j])
+				record = append(record, sb.String())
+				markQuoted()
+				line = line[j+delimLen:]
+				pos += j + delimLen
+			}
+			break
+		}
+	}
+}
+
+// ReadAll reads the remaining records
+func (r *Reader) ReadAll() ([][]string, error) {
+	var records [][]string
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return records, err
+		}
+		records = append(records, rec)
+	}
+}
@@ -0,0 +1,308 @@
+/**
+ * MIT License
+ *
+ * Copyright (c) 2025 Viki (VN - initials of my first and last name)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ *
+ * Contact: contact@viki.design
+ * Website: https://www.viki.design
+ *
+ */
+package csvio
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Every row of text with its format, and the reader after io.EOF
+func readRows(t *testing.T, text string, d Dialect) ([][]string, []*RowFormat, *Reader) {
+	t.Helper()
+	r := NewReader(strings.NewReader(text), d)
+	r.FieldsPerRecord = -1
+	var rows [][]string
+	var formats []*RowFormat
+	for {
+		row, f, err := r.ReadRow()
+		if err == io.EOF {
+			return rows, formats, r
+		}
+		if err != nil {
+			t.Fatalf("ReadRow(%q): %v", text, err)
+		}
+		rows = append(rows, row)
+		formats = append(formats, f)
+	}
+}
+
+func TestReadQuoted(t *testing.T) {
+	semicolon := Dialect{Delimiter: ';', Quote: '"'}
+	single := Dialect{Delimiter: ',', Quote: '\''}
+	tests := []struct {
+		text   string
+		d      Dialect
+		want   []string
+		quoted []bool
+	}{
+		{"a,b,c\n", DefaultDialect, []string{"a", "b", "c"}, nil},
+		{",,\n", DefaultDialect, []string{"", "", ""}, nil},
+		{"a,\"b \"\"c\"\" d\",e\n", DefaultDialect, []string{"a", `b "c" d`, "e"}, []bool{false, true, false}},
+		{"\"\",\"\"\n", DefaultDialect, []string{"", ""}, []bool{true, true}},
+		{"\"a,b\",c\n", DefaultDialect, []string{"a,b", "c"}, []bool{true, false}},
+		{"\"line 1\nline 2\",x\n", DefaultDialect, []string{"line 1\nline 2", "x"}, []bool{true, false}},
+		// A line break inside a field is read as "\n", like encoding/csv does
+		{"\"line 1\r\nline 2\",x\r\n", DefaultDialect, []string{"line 1\nline 2", "x"}, []bool{true, false}},
+		{"\"\"\"\"\n", DefaultDialect, []string{`"`}, []bool{true}},
+		{"\"a;b\";c\n", semicolon, []string{"a;b", "c"}, []bool{true, false}},
+		{"'it''s',\"x\"\n", single, []string{"it's", `"x"`}, []bool{true, false}},
+		// Last line without newline
+		{"a,\"b\"", DefaultDialect, []string{"a", "b"}, []bool{false, true}},
+	}
+	for _, tt := range tests {
+		r := NewReader(strings.NewReader(tt.text), tt.d)
+		row, f, err := r.ReadRow()
+		if err != nil {
+			t.Errorf("ReadRow(%q): %v", tt.text, err)
+			continue
+		}
+		if !reflect.DeepEqual(row, tt.want) || !reflect.DeepEqual(f.Quoted, tt.quoted) {
+			t.Errorf("ReadRow(%q) = %q quoted %v, want %q quoted %v", tt.text, row, f.Quoted, tt.want, tt.quoted)
+		}
+		if _, _, err := r.ReadRow(); err != io.EOF {
+			t.Errorf("ReadRow(%q) after the row: err = %v, want io.EOF", tt.text, err)
+		}
+	}
+}
+
+func TestReadFormats(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		texts   []string
+		endings []string
+		prefix  []string
+		tail    string
+	}{
+		{"lf", "a,b\n1,2\n", []string{"a,b", "1,2"}, []string{"\n", "\n"}, []string{"", ""}, "\n"},
+		{"crlf", "a,b\r\n1,2\r\n", []string{"a,b", "1,2"}, []string{"\r\n", "\r\n"}, []string{"", ""}, "\r\n"},
+		{"mixed", "a,b\r\n1,2\n3,4\r\n", []string{"a,b", "1,2", "3,4"}, []string{"\r\n", "\n", "\r\n"}, []string{"", "", ""}, "\r\n"},
+		{"no final newline", "a,b\n1,2", []string{"a,b", "1,2"}, []string{"\n", ""}, []string{"", ""}, ""},
+		{"blank lines", "a\n\n\r\nb\n\n", []string{"a", "b"}, []string{"\n", "\n"}, []string{"", "\n\r\n"}, "\n\n"},
+		{"multi-line field", "\"x\r\ny\",z\r\nw,v\n", []string{"\"x\r\ny\",z", "w,v"}, []string{"\r\n", "\n"}, []string{"", ""}, "\n"},
+	}
+	for _, tt := range tests {
+		_, formats, r := readRows(t, tt.text, DefaultDialect)
+		var texts, endings, prefix []string
+		for _, f := range formats {
+			texts = append(texts, f.Text)
+			endings = append(endings, f.Ending)
+			prefix = append(prefix, f.Prefix)
+		}
+		if !reflect.DeepEqual(texts, tt.texts) || !reflect.DeepEqual(endings, tt.endings) || !reflect.DeepEqual(prefix, tt.prefix) {
+			t.Errorf("%s: texts %q endings %q prefixes %q, want %q %q %q", tt.name, texts, endings, prefix, tt.texts, tt.endings, tt.prefix)
+		}
+		if r.Tail() != tt.tail {
+			t.Errorf("%s: Tail() = %q, want %q", tt.name, r.Tail(), tt.tail)
+		}
+	}
+}
+
+func TestReadBOM(t *testing.T) {
+	tests := []struct {
+		text  string
+		bom   bool
+		first string
+	}{
+		{"\xEF\xBB\xBFa,b\n1,2\n", true, "a"},
+		{"\xEF\xBB\xBF\"a\",b\n", true, "a"},
+		{"a,b\n", false, "a"},
+		// Only at the start of the file
+		{"\n\xEF\xBB\xBFa,b\n", false, "\xEF\xBB\xBFa"},
+	}
+	for _, tt := range tests {
+		rows, _, r := readRows(t, tt.text, DefaultDialect)
+		if r.BOM() != tt.bom || rows[0][0] != tt.first {
+			t.Errorf("%q: BOM() = %v, first field %q, want %v %q", tt.text, r.BOM(), rows[0][0], tt.bom, tt.first)
+		}
+	}
+}
+
+func TestReadFieldCount(t *testing.T) {
+	r := NewReader(strings.NewReader("a,b\n1,2\n3\n"), DefaultDialect)
+	for i := 0; i < 2; i++ {
+		if _, err := r.Read(); err != nil {
+			t.Fatalf("row %d: %v", i, err)
+		}
+	}
+	row, err := r.Read()
+	perr, ok := err.(*ParseError)
+	if !ok || perr.Err != ErrFieldCount || perr.Line != 3 {
+		t.Fatalf("short row: err = %v", err)
+	}
+	// The row is returned with its error
+	if !reflect.DeepEqual(row, []string{"3"}) {
+		t.Fatalf("short row = %q", row)
+	}
+}
+
+func TestReadStrictQuotes(t *testing.T) {
+	tests := []struct {
+		text string
+		err  error
+		line int
+		col  int
+	}{
+		{"a,b\"c\n", ErrBareQuote, 1, 4},
+		{"a,\"b\"c\n", ErrQuote, 1, 6},
+		{"x\n\"a\nb\n", ErrQuote, 3, 3},
+	}
+	for _, tt := range tests {
+		r := NewReader(strings.NewReader(tt.text), DefaultDialect)
+		r.FieldsPerRecord = -1
+		var err error
+		for err == nil {
+			_, err = r.Read()
+		}
+		perr, ok := err.(*ParseError)
+		if !ok || perr.Err != tt.err || perr.Line != tt.line || perr.Column != tt.col {
+			t.Errorf("%q: err = %v, want %v on line %d, column %d", tt.text, err, tt.err, tt.line, tt.col)
+		}
+	}
+}
+
+// A row of a lenient read: its fields, its text in the file and the problem
+// reported with it ( err nil: none )
+type lenientRow struct {
+	fields []string
+	text   string
+	err    error
+	line   int
+	col    int
+}
+
+func TestReadLenient(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []lenientRow
+	}{
+		{"ragged rows", "a,b\n1\n2,3,4\n5,6\n", []lenientRow{
+			{[]string{"a", "b"}, "a,b", nil, 0, 0},
+			{[]string{"1"}, "1", ErrFieldCount, 2, 1},
+			{[]string{"2", "3", "4"}, "2,3,4", ErrFieldCount, 3, 1},
+			{[]string{"5", "6"}, "5,6", nil, 0, 0},
+		}},
+		{"bare quote", "a,b\nx\"y,z\n", []lenientRow{
+			{[]string{"a", "b"}, "a,b", nil, 0, 0},
+			{[]string{`x"y`, "z"}, `x"y,z`, ErrBareQuote, 2, 2},
+		}},
+		{"text after the closing quote", "a,b\n\"x\"y,z\n1,2\n", []lenientRow{
+			{[]string{"a", "b"}, "a,b", nil, 0, 0},
+			{[]string{"xy", "z"}, `"x"y,z`, ErrQuote, 2, 4},
+			{[]string{"1", "2"}, "1,2", nil, 0, 0},
+		}},
+		// Only the first problem of a row is reported
+		{"two problems", "a,b\n\"x\"y,z\"\n", []lenientRow{
+			{[]string{"a", "b"}, "a,b", nil, 0, 0},
+			{[]string{"xy", `z"`}, `"x"y,z"`, ErrQuote, 2, 4},
+		}},
+		// The quote is plain, the lines after it are rows of their own
+		{"unclosed quote", "a,b\n\"x,y\n1\n3,4\n", []lenientRow{
+			{[]string{"a", "b"}, "a,b", nil, 0, 0},
+			{[]string{`"x`, "y"}, `"x,y`, ErrQuote, 2, 1},
+			{[]string{"1"}, "1", ErrFieldCount, 3, 1},
+			{[]string{"3", "4"}, "3,4", nil, 0, 0},
+		}},
+		{"unclosed quote in a later field", "a,b\n1,\"x\n2,3\n", []lenientRow{
+			{[]string{"a", "b"}, "a,b", nil, 0, 0},
+			{[]string{"1", `"x`}, `1,"x`, ErrQuote, 2, 3},
+			{[]string{"2", "3"}, "2,3", nil, 0, 0},
+		}},
+		{"unclosed quote, crlf", "a,b\r\n\"x,y\r\n1,2\r\n", []lenientRow{
+			{[]string{"a", "b"}, "a,b", nil, 0, 0},
+			{[]string{`"x`, "y"}, `"x,y`, ErrQuote, 2, 1},
+			{[]string{"1", "2"}, "1,2", nil, 0, 0},
+		}},
+		// On the last line there is nothing to give back: the field runs to
+		// the end
+		{"unclosed quote on the last line", "a,b\n1,\"x\n", []lenientRow{
+			{[]string{"a", "b"}, "a,b", nil, 0, 0},
+			{[]string{"1", "x"}, `1,"x`, ErrQuote, 2, 6},
+		}},
+		// A field over several lines that does not end with its quote
+		{"stray quote takes lines", "a,b\n\"x,y\nsay \"hi\" there,2\n3,4\n", []lenientRow{
+			{[]string{"a", "b"}, "a,b", nil, 0, 0},
+			{[]string{`"x`, "y"}, `"x,y`, ErrQuote, 2, 1},
+			{[]string{`say "hi" there`, "2"}, `say "hi" there,2`, ErrBareQuote, 3, 5},
+			{[]string{"3", "4"}, "3,4", nil, 0, 0},
+		}},
+		// Quotes after the plain one still open fields on the next lines
+		{"quoted field after a reparse", "a,b\n\"x,y\n\"1\n2\",3\n", []lenientRow{
+			{[]string{"a", "b"}, "a,b", nil, 0, 0},
+			{[]string{`"x`, "y"}, `"x,y`, ErrQuote, 2, 1},
+			{[]string{"1\n2", "3"}, "\"1\n2\",3", nil, 0, 0},
+		}},
+		{"valid field over lines", "a,b\n\"x\ny\",2\n", []lenientRow{
+			{[]string{"a", "b"}, "a,b", nil, 0, 0},
+			{[]string{"x\ny", "2"}, "\"x\ny\",2", nil, 0, 0},
+		}},
+	}
+	for _, tt := range tests {
+		r := NewReader(strings.NewReader(tt.text), DefaultDialect)
+		r.Lenient = true
+		var got []lenientRow
+		for {
+			row, f, err := r.ReadRow()
+			if err == io.EOF {
+				break
+			}
+			g := lenientRow{fields: row}
+			if f != nil {
+				g.text = f.Text
+			}
+			if err != nil {
+				perr, ok := err.(*ParseError)
+				if !ok {
+					t.Fatalf("%s: ReadRow: %v", tt.name, err)
+				}
+				g.err, g.line, g.col = perr.Err, perr.Line, perr.Column
+			}
+			got = append(got, g)
+			if len(got) > len(tt.want) {
+				break
+			}
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: read %d rows %v, want %d", tt.name, len(got), got, len(tt.want))
+			continue
+		}
+		for i, g := range got {
+			if !reflect.DeepEqual(g, tt.want[i]) {
+				t.Errorf("%s: row %d = %q %q %v line %d col %d, want %q %q %v line %d col %d", tt.name, i,
+					g.fields, g.text, g.err, g.line, g.col,
+					tt.want[i].fields, tt.want[i].text, tt.want[i].err, tt.want[i].line, tt.want[i].col)
+			}
+		}
+	}
+}
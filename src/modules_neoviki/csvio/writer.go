@@ -0,0 +1,245 @@
+/**
+ * MIT License
+ *
+ * Copyright (c) 2025 Viki (VN - initials of my first and last name)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ *
+ * Contact: contact@viki.design
+ * Website: https://www.viki.design
+ *
+ */
+
+package csvio
+
+import (
+	"bufio"
+	"io"
+	"strings"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/transform"
+)
+
+// Writer works like encoding/csv.Writer, with the delimiter, quote character,
+// line ending and encoding taken from a Dialect. WriteRow / Finish write a
+// whole file the way it was read.
+type Writer struct {
+	Dialect Dialect
+
+	w *bufio.Writer
+
+	// Set for encodings other than UTF-8
+	encoder *transform.Writer
+	check   *encoding.Encoder
+
+	// Fields the encoding can not store, and where the first one was
+	lost        int
+	lostRow     int
+	lostCol     int
+	rowsWritten int
+
+	started bool
+
+	// Line ending of the previous row, written when another row follows
+	pending string
+
+	// First error of a write or flush, nothing is written after it
+	err error
+}
+
+func NewWriter(w io.Writer, d Dialect) *Writer {
+	cw := &Writer{Dialect: d}
+	if cw.encoder = newEncodingWriter(w, d.Encoding); cw.encoder != nil {
+		cw.check = lookupEncoding(d.Encoding).NewEncoder()
+		w = cw.encoder
+	}
+	cw.w = bufio.NewWriter(w)
+	return cw
+}
+
+// Lost is the number of fields with characters the encoding can not store
+// ( they were replaced ), row and col tell where the first one is
+func (w *Writer) Lost() (n int, row int, col int) {
+	return w.lost, w.lostRow, w.lostCol
+}
+
+func (w *Writer) lineEnding() string {
+	if w.Dialect.LineEnding == "" {
+		return "\n"
+	}
+	return w.Dialect.LineEnding
+}
+
+// A field is quoted when it contains the delimiter, the quote character or a
+// line break, or starts with a space ( which readers may trim )
+func (w *Writer) fieldNeedsQuotes(field string) bool {
+	if field == "" {
+		return false
+	}
+	if strings.ContainsRune(field, w.Dialect.Delimiter) || strings.ContainsRune(field, w.Dialect.Quote) || strings.ContainsAny(field, "\r\n") {
+		return true
+	}
+	return field[0] == ' ' || field[0] == '\t'
+}
+
+// An unchanged row the encoding can not store is written from its fields,
+// which counts them in Lost
+func (w *Writer) storable(text string) bool {
+	if w.check == nil {
+		return true
+	}
+	_, err := w.check.String(text)
+	return err == nil
+}
+
+// All output goes through here, the first error is kept ( see Error )
+func (w *Writer) write(s string) {
+	if w.err != nil {
+		return
+	}
+	if _, err := w.w.WriteString(s); err != nil {
+		w.err = err
+	}
+}
+
+// BOM and the line ending of the previous row
+func (w *Writer) begin() {
+	if !w.started {
+		w.started = true
+		if w.Dialect.BOM {
+			w.write(string(utf8BOM))
+		}
+	}
+	w.write(w.pending)
+	w.pending = ""
+}
+
+// quoted: fields that were quoted in the file ( may be nil )
+func (w *Writer) writeFields(record []string, quoted []bool) error {
+	quote := string(w.Dialect.Quote)
+	for i, field := range record {
+		if i > 0 {
+			w.write(string(w.Dialect.Delimiter))
+		}
+		if w.check != nil {
+			if _, err := w.check.String(field); err != nil {
+				if w.lost == 0 {
+					w.lostRow, w.lostCol = w.rowsWritten, i
+				}
+				w.lost++
+				field = replaceUnsupported(w.check, field)
+			}
+		}
+		if !w.Dialect.QuoteAll && !(i < len(quoted) && quoted[i]) && !w.fieldNeedsQuotes(field) {
+			w.write(field)
+			continue
+		}
+		field = strings.ReplaceAll(field, quote, quote+quote)
+		w.write(quote + field + quote)
+	}
+	return w.err
+}
+
+// Write writes one record and its line ending
+func (w *Writer) Write(record []string) error {
+	w.begin()
+	if err := w.writeFields(record, nil); err != nil {
+		return err
+	}
+	w.write(w.lineEnding())
+	w.rowsWritten++
+	return w.err
+}
+
+// WriteRow writes a row as it was read if it was not changed ( f.Text is
+// set ), otherwise from its fields, quoting the fields that were quoted
+// before. The line ending is written when the next row follows ( or by
+// Finish ), so the last row can end the way the file did.
+func (w *Writer) WriteRow(record []string, f *RowFormat) error {
+	w.begin()
+	if f == nil {
+		if err := w.writeFields(record, nil); err != nil {
+			return err
+		}
+		w.pending = w.lineEnding()
+		w.rowsWritten++
+		return nil
+	}
+
+	w.write(f.Prefix)
+	if f.Text != "" && w.storable(f.Text) {
+		w.write(f.Text)
+	} else if err := w.writeFields(record, f.Quoted); err != nil {
+		return err
+	}
+	w.pending = f.Ending
+	if w.pending == "" {
+		w.pending = w.lineEnding()
+	}
+	w.rowsWritten++
+	return w.err
+}
+
+// Finish ends the file after the last WriteRow with Dialect.Tail and flushes
+func (w *Writer) Finish() error {
+	if !w.started {
+		w.begin()
+	}
+	w.pending = ""
+	w.write(w.Dialect.Tail)
+	return w.flush()
+}
+
+func (w *Writer) flush() error {
+	if w.err != nil {
+		return w.err
+	}
+	if err := w.w.Flush(); err != nil {
+		w.err = err
+		return err
+	}
+	if w.encoder != nil {
+		// Whatever the transformer still holds
+		w.err = w.encoder.Close()
+	}
+	return w.err
+}
+
+// Flush writes buffered data to the underlying writer, see Error
+func (w *Writer) Flush() {
+	w.flush()
+}
+
+// Error reports the first error of a previous Write or Flush
+func (w *Writer) Error() error {
+	return w.err
+}
+
+// WriteAll writes the records and flushes
+func (w *Writer) WriteAll(records [][]string) error {
+	for _, rec := range records {
+		if err := w.Write(rec); err != nil {
+			return err
+		}
+	}
+	return w.flush()
+}
@@ -0,0 +1,187 @@
+/**
+ * MIT License
+ *
+ * Copyright (c) 2025 Viki (VN - initials of my first and last name)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ *
+ * Contact: contact@viki.design
+ * Website: https://www.viki.design
+ *
+ */
+package csvio
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// Rows written back with their formats, the way document.Write does it
+func writeRows(t *testing.T, d Dialect, rows [][]string, formats []*RowFormat) string {
+	t.Helper()
+	var b bytes.Buffer
+	w := NewWriter(&b, d)
+	for i, row := range rows {
+		if err := w.WriteRow(row, formats[i]); err != nil {
+			t.Fatalf("WriteRow: %v", err)
+		}
+	}
+	if err := w.Finish(); err != nil {
+		t.Fatalf("Finish: %v", err)
+	}
+	return b.String()
+}
+
+// Read text in its sniffed dialect
+func readSniffed(t *testing.T, text string) (Dialect, [][]string, []*RowFormat) {
+	t.Helper()
+	d := Sniff([]byte(text))
+	rows, formats, r := readRows(t, text, d)
+	d.BOM, d.Tail = r.BOM(), r.Tail()
+	return d, rows, formats
+}
+
+func TestRoundTrip(t *testing.T) {
+	tests := []string{
+		"name,age\nann,30\nbob,40\n",
+		"name,age\r\nann,30\r\nbob,40\r\n",
+		"name,age\r\nann,30\nbob,40\r\n",
+		"\xEF\xBB\xBFname,age\r\nann,30\r\n",
+		"name,age\nann,30",
+		"name,age\n\nann,30\n\r\n\n",
+		"\"name\",\"age\"\n\"ann\",\"30\"\n",
+		"name,note\nann,\"say \"\"hi\"\"\"\nbob,\"two\r\nlines\"\n",
+		"name;note\nann;\"a;b\"\nbob;'x'\n",
+		"name,note\n'ann','a, b'\n'bob','it''s'\n",
+		"name\tage\nann\t30\n",
+		// Quotes the writer would not have used
+		"\"name\",age\n\"ann\",30\nbob,\"40\"\n",
+	}
+	for _, text := range tests {
+		d, rows, formats := readSniffed(t, text)
+		if got := writeRows(t, d, rows, formats); got != text {
+			t.Errorf("round trip of %q wrote %q", text, got)
+		}
+	}
+}
+
+// Changed rows ( Text cleared ) are written from their fields, the others as
+// they were
+func TestWriteChangedRows(t *testing.T) {
+	tests := []struct {
+		text    string
+		changed int
+		row     []string
+		want    string
+	}{
+		// Fields that were quoted stay quoted, new ones only when they need it
+		{"a,b\r\n\"x\",y\r\n1,2\r\n", 1, []string{"x2", "y,z"}, "a,b\r\n\"x2\",\"y,z\"\r\n1,2\r\n"},
+		{"a,b\n1,2\n3,4\n", 1, []string{"1", `say "hi"`}, "a,b\n1,\"say \"\"hi\"\"\"\n3,4\n"},
+		// The row keeps its own line ending
+		{"a,b\r\n1,2\n3,4\r\n", 1, []string{"5", "6"}, "a,b\r\n5,6\n3,4\r\n"},
+		// A last row without newline stays without
+		{"a,b\n1,2", 1, []string{"5", "6"}, "a,b\n5,6"},
+		// Blank lines before a row are kept
+		{"a,b\n\n1,2\n", 1, []string{"5", "6"}, "a,b\n\n5,6\n"},
+		// A row that got more fields
+		{"a,b\n\"1\",2\n", 1, []string{"1", "2", "3"}, "a,b\n\"1\",2,3\n"},
+		{"\xEF\xBB\xBFa,b\n1,2\n", 0, []string{"c", "d"}, "\xEF\xBB\xBFc,d\n1,2\n"},
+	}
+	for _, tt := range tests {
+		d, rows, formats := readSniffed(t, tt.text)
+		rows[tt.changed] = tt.row
+		formats[tt.changed].Text = ""
+		if got := writeRows(t, d, rows, formats); got != tt.want {
+			t.Errorf("%q with row %d changed: wrote %q, want %q", tt.text, tt.changed, got, tt.want)
+		}
+	}
+}
+
+// New rows ( no format ) get the line ending of the dialect
+func TestWriteNewRows(t *testing.T) {
+	d, rows, formats := readSniffed(t, "a,b\r\n1,2")
+	rows = append(rows, []string{"3", "4"})
+	formats = append(formats, nil)
+	if got, want := writeRows(t, d, rows, formats), "a,b\r\n1,2\r\n3,4"; got != want {
+		t.Fatalf("wrote %q, want %q", got, want)
+	}
+}
+
+func TestWriteQuoting(t *testing.T) {
+	semicolon := Dialect{Delimiter: ';', Quote: '\'', LineEnding: "\r\n"}
+	quoteAll := DefaultDialect
+	quoteAll.QuoteAll = true
+	tests := []struct {
+		d    Dialect
+		row  []string
+		want string
+	}{
+		{DefaultDialect, []string{"a", "", "b c"}, "a,,b c\n"},
+		{DefaultDialect, []string{"a,b", `"q"`, "x\ny", "r\rs"}, "\"a,b\",\"\"\"q\"\"\",\"x\ny\",\"r\rs\"\n"},
+		// Readers may trim leading blanks
+		{DefaultDialect, []string{" a", "\tb", "c "}, "\" a\",\"\tb\",c \n"},
+		{semicolon, []string{"a,b", "c;d", "it's", `"x"`}, "a,b;'c;d';'it''s';\"x\"\r\n"},
+		{quoteAll, []string{"a", ""}, "\"a\",\"\"\n"},
+	}
+	for _, tt := range tests {
+		var b bytes.Buffer
+		w := NewWriter(&b, tt.d)
+		if err := w.WriteAll([][]string{tt.row}); err != nil {
+			t.Fatalf("WriteAll: %v", err)
+		}
+		if b.String() != tt.want {
+			t.Errorf("%q wrote %q, want %q", tt.row, b.String(), tt.want)
+		}
+	}
+}
+
+// Fails every write
+type failWriter struct{}
+
+var errDiskFull = errors.New("disk full")
+
+func (failWriter) Write(p []byte) (int, error) { return 0, errDiskFull }
+
+func TestWriterError(t *testing.T) {
+	w := NewWriter(failWriter{}, DefaultDialect)
+	// Buffered, the error comes with the flush
+	if err := w.Write([]string{"a", "b"}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	w.Flush()
+	if err := w.Error(); err != errDiskFull {
+		t.Fatalf("Error() after Flush = %v, want %v", err, errDiskFull)
+	}
+	// The error stays, nothing is written after it
+	if err := w.Write([]string{"c"}); err != errDiskFull {
+		t.Fatalf("Write after the error = %v", err)
+	}
+	if err := w.Finish(); err != errDiskFull {
+		t.Fatalf("Finish after the error = %v", err)
+	}
+
+	// A row too big for the buffer fails in WriteRow already
+	w = NewWriter(failWriter{}, DefaultDialect)
+	if err := w.WriteRow([]string{string(bytes.Repeat([]byte("x"), 8192))}, nil); err != errDiskFull {
+		t.Fatalf("WriteRow of a long row = %v, want %v", err, errDiskFull)
+	}
+}
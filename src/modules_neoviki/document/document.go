@@ -0,0 +1,474 @@
+/**
+ * MIT License
+ *
+ * Copyright (c) 2025 Viki (VN - initials of my first and last name)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ *
+ * Contact: contact@viki.design
+ * Website: https://www.viki.design
+ *
+ */
+
+// Package document is a csv file in memory: its rows, the dialect it is
+// written in and whether it has unsaved changes. Rows that were not changed
+// are written back the way they were read ( see csvio.RowFormat ).
+//
+// Every change is reported to the OnChange listeners, so a user interface
+// ( or an undo history, a journal ... ) can follow the document.
+//
+// Row 0 is always the header row. A file without a header gets column
+// labels in row 0 instead ( see Label ), they can not be edited and are not
+// written.
+package document
+
+import (
+	"errors"
+	"strconv"
+
+	"neoviki_spreadsheet/modules_neoviki/csvio"
+)
+
+var (
+	ErrRange    = errors.New("row or column out of range")
+	ErrHeader   = errors.New("the header row can only be changed with SetHeader")
+	ErrReadOnly = errors.New("row 0 holds column labels, they are not part of the file")
+)
+
+// ChangeKind tells what a Change did
+type ChangeKind int
+
+const (
+	CellChanged ChangeKind = iota
+	RowChanged
+	RowInserted
+	RowDeleted
+	ColumnInserted
+	ColumnDeleted
+	RowsReordered
+	HeaderChanged
+	DialectChanged
+
+	// Rows added by AppendRows, the document stays clean
+	RowsAppended
+)
+
+// Change is sent to the listeners after the document changed
+type Change struct {
+	Kind ChangeKind
+
+	// Cell, row or column that changed. RowsReordered: the first row that
+	// moved, RowsAppended: the first new row.
+	Row int
+	Col int
+
+	// RowsReordered: Order[i] is the index ( relative to Row ) of the row
+	// that moved to Row+i
+	Order []int
+}
+
+// Document holds the rows of a csv file, create one with New, Read or Open
+type Document struct {
+	// Label names column c in row 0 of a file without header, nil for
+	// A, B, C ... Call Relabel after changing it.
+	Label func(c int) string
+
+	rows    [][]string
+	formats []*csvio.RowFormat
+	dialect csvio.Dialect
+
+	// Widest row
+	cols int
+
+	dirty     bool
+	listeners []func(Change)
+
+	// Fields the encoding could not store in the last Write
+	lost, lostRow, lostCol int
+}
+
+// New returns a document with one row, the header ( format: how it was
+// read, may be nil ). Without a header in the dialect, header is the first
+// row of data and row 0 gets labels.
+func New(d csvio.Dialect, header []string, format *csvio.RowFormat) *Document {
+	doc := &Document{dialect: d, cols: len(header)}
+	if d.HasHeader {
+		doc.rows = [][]string{header}
+		doc.formats = []*csvio.RowFormat{format}
+	} else {
+		// Labels as wide as the first row
+		doc.rows = [][]string{make([]string, len(header)), header}
+		doc.formats = []*csvio.RowFormat{nil, format}
+	}
+	doc.Relabel()
+	return doc
+}
+
+// OnChange adds a listener, it is called after every change
+func (d *Document) OnChange(fn func(Change)) {
+	d.listeners = append(d.listeners, fn)
+}
+
+func (d *Document) changed(c Change) {
+	if c.Kind != RowsAppended {
+		d.dirty = true
+	}
+	for _, fn := range d.listeners {
+		fn(c)
+	}
+}
+
+// Dirty reports whether the document changed since it was read or saved
+func (d *Document) Dirty() bool {
+	return d.dirty
+}
+
+// SetDirty marks the document as changed ( or as saved )
+func (d *Document) SetDirty(dirty bool) {
+	d.dirty = dirty
+}
+
+// NumRows is the number of rows, the header row included
+func (d *Document) NumRows() int {
+	return len(d.rows)
+}
+
+// NumCols is the number of fields in the widest row, rows may be shorter
+// ( or longer ) than the header
+func (d *Document) NumCols() int {
+	return d.cols
+}
+
+// Rows returns the rows themselves, they must not be changed
+func (d *Document) Rows() [][]string {
+	return d.rows
+}
+
+// Formats returns how every row was read ( nil for new rows ), they must
+// not be changed
+func (d *Document) Formats() []*csvio.RowFormat {
+	return d.formats
+}
+
+// Row returns the cells of row r, nil if there is no such row
+func (d *Document) Row(r int) []string {
+	if r < 0 || r >= len(d.rows) {
+		return nil
+	}
+	return append([]string(nil), d.rows[r]...)
+}
+
+// Cell returns the text of a cell, "" for cells a short row does not have
+func (d *Document) Cell(r int, c int) string {
+	if r < 0 || r >= len(d.rows) || c < 0 || c >= len(d.rows[r]) {
+		return ""
+	}
+	return d.rows[r][c]
+}
+
+// Dialect is the dialect the document is written in
+func (d *Document) Dialect() csvio.Dialect {
+	return d.dialect
+}
+
+// HasHeader reports whether row 0 is part of the file ( and not labels )
+func (d *Document) HasHeader() bool {
+	return d.dialect.HasHeader
+}
+
+// SetDialect changes how the document is written. Whether there is a
+// header row is kept, see SetHeader.
+func (d *Document) SetDialect(dialect csvio.Dialect) error {
+	if err := dialect.Validate(); err != nil {
+		return err
+	}
+	dialect.HasHeader = d.dialect.HasHeader
+	if dialect == d.dialect {
+		return nil
+	}
+	if dialect.Delimiter != d.dialect.Delimiter || dialect.Quote != d.dialect.Quote {
+		// Rows written with another delimiter / quote character have to be rebuilt
+		for _, f := range d.formats {
+			if f != nil {
+				f.Text = ""
+			}
+		}
+	}
+	d.dialect = dialect
+	d.changed(Change{Kind: DialectChanged})
+	return nil
+}
+
+// SetTail sets what follows the last row ( see csvio.Dialect.Tail ), for
+// loaders that learn it after the last row. It is not a change.
+func (d *Document) SetTail(tail string) {
+	d.dialect.Tail = tail
+}
+
+// A row 0 change other than through SetHeader
+func (d *Document) checkRow(r int) error {
+	switch {
+	case r < 0 || r >= len(d.rows):
+		return ErrRange
+	case r == 0 && !d.dialect.HasHeader:
+		return ErrReadOnly
+	}
+	return nil
+}
+
+// The raw text of a changed row can not be used anymore
+func (d *Document) rowChanged(r int) {
+	if f := d.formats[r]; f != nil {
+		f.Text = ""
+	}
+}
+
+func (d *Document) grow(row []string) {
+	if len(row) > d.cols {
+		d.cols = len(row)
+	}
+}
+
+// SetCell changes one cell, a short row grows up to it
+func (d *Document) SetCell(r int, c int, text string) error {
+	if err := d.checkRow(r); err != nil {
+		return err
+	}
+	if c < 0 {
+		return ErrRange
+	}
+	for len(d.rows[r]) <= c {
+		d.rows[r] = append(d.rows[r], "")
+	}
+	d.rows[r][c] = text
+	d.grow(d.rows[r])
+	d.rowChanged(r)
+	d.changed(Change{Kind: CellChanged, Row: r, Col: c})
+	return nil
+}
+
+// SetRow replaces all cells of a row, the row may get another length
+func (d *Document) SetRow(r int, cells []string) error {
+	if err := d.checkRow(r); err != nil {
+		return err
+	}
+	d.rows[r] = append([]string(nil), cells...)
+	d.grow(d.rows[r])
+	d.rowChanged(r)
+	d.changed(Change{Kind: RowChanged, Row: r})
+	return nil
+}
+
+// InsertRow inserts a row at index at ( 1 .. NumRows ), format is how the
+// row was written in a file ( nil for a new row )
+func (d *Document) InsertRow(at int, cells []string, format *csvio.RowFormat) error {
+	if at == 0 {
+		return ErrHeader
+	}
+	if at < 0 || at > len(d.rows) {
+		return ErrRange
+	}
+	d.rows = append(d.rows, nil)
+	copy(d.rows[at+1:], d.rows[at:])
+	d.rows[at] = append([]string(nil), cells...)
+	d.grow(d.rows[at])
+
+	d.formats = append(d.formats, nil)
+	copy(d.formats[at+1:], d.formats[at:])
+	d.formats[at] = format
+
+	d.changed(Change{Kind: RowInserted, Row: at})
+	return nil
+}
+
+// DeleteRow deletes a row ( not the header ) and returns its cells and
+// format, InsertRow puts it back
+func (d *Document) DeleteRow(at int) ([]string, *csvio.RowFormat, error) {
+	if at == 0 {
+		return nil, nil, ErrHeader
+	}
+	if at < 0 || at >= len(d.rows) {
+		return nil, nil, ErrRange
+	}
+	row, format := d.rows[at], d.formats[at]
+	d.rows = append(d.rows[:at], d.rows[at+1:]...)
+	d.formats = append(d.formats[:at], d.formats[at+1:]...)
+
+	d.changed(Change{Kind: RowDeleted, Row: at})
+	return row, format, nil
+}
+
+// A column was inserted ( or deleted ) at c in row r
+func (d *Document) colChanged(r int, c int, inserted bool) {
+	f := d.formats[r]
+	if f == nil {
+		return
+	}
+	f.Text = ""
+	if c >= len(f.Quoted) {
+		return
+	}
+	if inserted {
+		f.Quoted = append(f.Quoted, false)
+		copy(f.Quoted[c+1:], f.Quoted[c:])
+		f.Quoted[c] = false
+	} else {
+		f.Quoted = append(f.Quoted[:c], f.Quoted[c+1:]...)
+	}
+}
+
+// InsertColumn inserts a column at index at ( 0 .. NumCols ), cells[i] is
+// the text for row i ( missing cells are empty ). Short rows are padded up
+// to at.
+func (d *Document) InsertColumn(at int, cells []string) error {
+	if at < 0 || at > d.cols {
+		return ErrRange
+	}
+	for i := range d.rows {
+		for len(d.rows[i]) < at {
+			d.rows[i] = append(d.rows[i], "")
+		}
+		text := ""
+		if i < len(cells) {
+			text = cells[i]
+		}
+		d.rows[i] = append(d.rows[i], "")
+		copy(d.rows[i][at+1:], d.rows[i][at:])
+		d.rows[i][at] = text
+		d.colChanged(i, at, true)
+	}
+	d.cols++
+	d.Relabel()
+	d.changed(Change{Kind: ColumnInserted, Col: at})
+	return nil
+}
+
+// DeleteColumn deletes a column and returns its cells ( one per row, ""
+// for short rows ), InsertColumn puts it back
+func (d *Document) DeleteColumn(at int) ([]string, error) {
+	if at < 0 || at >= d.cols {
+		return nil, ErrRange
+	}
+	cells := make([]string, len(d.rows))
+	for i := range d.rows {
+		if at >= len(d.rows[i]) {
+			// Short row, nothing to delete
+			continue
+		}
+		cells[i] = d.rows[i][at]
+		d.rows[i] = append(d.rows[i][:at], d.rows[i][at+1:]...)
+		d.colChanged(i, at, false)
+	}
+	d.cols--
+	d.Relabel()
+	d.changed(Change{Kind: ColumnDeleted, Col: at})
+	return cells, nil
+}
+
+// ReorderRows moves rows around: order[i] is the index ( relative to from )
+// of the row that moves to from+i. The header row can not move.
+func (d *Document) ReorderRows(from int, order []int) error {
+	if from < 1 || from+len(order) > len(d.rows) {
+		return ErrRange
+	}
+	seen := make([]bool, len(order))
+	for _, o := range order {
+		if o < 0 || o >= len(order) || seen[o] {
+			return ErrRange
+		}
+		seen[o] = true
+	}
+
+	rows := make([][]string, len(order))
+	formats := make([]*csvio.RowFormat, len(order))
+	for i, o := range order {
+		rows[i] = d.rows[from+o]
+		formats[i] = d.formats[from+o]
+	}
+	copy(d.rows[from:], rows)
+	copy(d.formats[from:], formats)
+	d.changed(Change{Kind: RowsReordered, Row: from, Order: append([]int(nil), order...)})
+	return nil
+}
+
+// SetHeader replaces row 0. hasHeader false turns it into labels as wide
+// as cells, the header is not part of the file then.
+func (d *Document) SetHeader(hasHeader bool, cells []string, format *csvio.RowFormat) {
+	d.dialect.HasHeader = hasHeader
+	d.rows[0] = append([]string(nil), cells...)
+	d.formats[0] = format
+	d.grow(d.rows[0])
+	d.Relabel()
+	d.changed(Change{Kind: HeaderChanged})
+}
+
+// Relabel renews the labels in row 0 of a file without header ( after
+// Label changed ), it is not a change of the file
+func (d *Document) Relabel() {
+	if d.dialect.HasHeader || len(d.rows) == 0 {
+		return
+	}
+	labels := make([]string, len(d.rows[0]))
+	for c := range labels {
+		labels[c] = d.label(c)
+	}
+	d.rows[0] = labels
+}
+
+func (d *Document) label(c int) string {
+	if d.Label != nil {
+		return d.Label(c)
+	}
+	return ColumnLetters(c)
+}
+
+// ColumnLetters is the spreadsheet name of column c: A .. Z, AA, AB ...
+func ColumnLetters(c int) string {
+	label := ""
+	for n := c + 1; n > 0; n = (n - 1) / 26 {
+		label = string(rune('A'+(n-1)%26)) + label
+	}
+	return label
+}
+
+// ColumnNumbers labels column c 1, 2, 3 ...
+func ColumnNumbers(c int) string {
+	return strconv.Itoa(c + 1)
+}
+
+// AppendRows adds rows read from the file to the end ( a loader that reads
+// in the background ), the document stays clean
+func (d *Document) AppendRows(rows [][]string, formats []*csvio.RowFormat) {
+	if len(rows) == 0 {
+		return
+	}
+	at := len(d.rows)
+	for i, row := range rows {
+		var f *csvio.RowFormat
+		if i < len(formats) {
+			f = formats[i]
+		}
+		d.rows = append(d.rows, row)
+		d.formats = append(d.formats, f)
+		d.grow(row)
+	}
+	d.changed(Change{Kind: RowsAppended, Row: at})
+}
@@ -0,0 +1,558 @@
+/**
+ * MIT License
+ *
+ * Copyright (c) 2025 Viki (VN - initials of my first and last name)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ *
+ * Contact: contact@viki.design
+ * Website: https://www.viki.design
+ *
+ */
+
+package document
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"neoviki_spreadsheet/modules_neoviki/csvio"
+)
+
+const sample = "name,age\r\nann,30\r\n\"bob, jr\",40\r\n"
+
+func readString(t *testing.T, text string, d csvio.Dialect) *Document {
+	t.Helper()
+	doc, err := Read(strings.NewReader(text), d)
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	return doc
+}
+
+// sample in the dialect sniffed from it ( "\r\n" line endings )
+func readSample(t *testing.T) *Document {
+	t.Helper()
+	return readString(t, sample, csvio.Sniff([]byte(sample)))
+}
+
+// Header a,b,c and a row with one field
+func shortRowDocument() *Document {
+	doc := New(csvio.DefaultDialect, []string{"a", "b", "c"}, nil)
+	doc.AppendRows([][]string{{"1"}}, nil)
+	return doc
+}
+
+func writeString(t *testing.T, doc *Document) string {
+	t.Helper()
+	var b bytes.Buffer
+	if err := doc.Write(&b); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	return b.String()
+}
+
+// Changes seen by a listener
+func record(doc *Document) *[]Change {
+	var changes []Change
+	doc.OnChange(func(c Change) { changes = append(changes, c) })
+	return &changes
+}
+
+func checkRows(t *testing.T, doc *Document, want [][]string) {
+	t.Helper()
+	if got := doc.Rows(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("rows = %q, want %q", got, want)
+	}
+	if len(doc.Formats()) != len(doc.Rows()) {
+		t.Fatalf("%d formats for %d rows", len(doc.Formats()), len(doc.Rows()))
+	}
+}
+
+func TestReadWriteUnchanged(t *testing.T) {
+	doc := readSample(t)
+	checkRows(t, doc, [][]string{{"name", "age"}, {"ann", "30"}, {"bob, jr", "40"}})
+	if doc.NumRows() != 3 || doc.NumCols() != 2 {
+		t.Fatalf("size = %d x %d", doc.NumRows(), doc.NumCols())
+	}
+	if doc.Dirty() {
+		t.Fatal("a document that was just read is dirty")
+	}
+	if got := writeString(t, doc); got != sample {
+		t.Fatalf("written %q, want %q", got, sample)
+	}
+}
+
+func TestReadEmpty(t *testing.T) {
+	doc := readString(t, "", csvio.DefaultDialect)
+	if doc.NumRows() != 1 || doc.NumCols() != 0 {
+		t.Fatalf("size = %d x %d", doc.NumRows(), doc.NumCols())
+	}
+}
+
+func TestReadError(t *testing.T) {
+	if _, err := Read(strings.NewReader("a,b\n1,2,3\n"), csvio.DefaultDialect); !errors.Is(err, csvio.ErrFieldCount) {
+		t.Fatalf("err = %v, want %v", err, csvio.ErrFieldCount)
+	}
+}
+
+func TestCell(t *testing.T) {
+	doc := shortRowDocument()
+	tests := []struct {
+		row, col int
+		want     string
+	}{
+		{0, 1, "b"},
+		{1, 0, "1"},
+		{1, 2, ""}, // short row
+		{2, 0, ""},
+		{-1, 0, ""},
+		{0, 3, ""},
+	}
+	for _, tt := range tests {
+		if got := doc.Cell(tt.row, tt.col); got != tt.want {
+			t.Errorf("Cell(%d, %d) = %q, want %q", tt.row, tt.col, got, tt.want)
+		}
+	}
+	if doc.Row(5) != nil {
+		t.Error("Row of a missing row is not nil")
+	}
+}
+
+func TestSetCell(t *testing.T) {
+	doc := readSample(t)
+	changes := record(doc)
+
+	if err := doc.SetCell(1, 1, "31"); err != nil {
+		t.Fatal(err)
+	}
+	if doc.Cell(1, 1) != "31" || !doc.Dirty() {
+		t.Fatalf("cell = %q, dirty = %v", doc.Cell(1, 1), doc.Dirty())
+	}
+	want := []Change{{Kind: CellChanged, Row: 1, Col: 1}}
+	if !reflect.DeepEqual(*changes, want) {
+		t.Fatalf("changes = %+v, want %+v", *changes, want)
+	}
+
+	// Only the changed row is written from its fields
+	if got := writeString(t, doc); got != "name,age\r\nann,31\r\n\"bob, jr\",40\r\n" {
+		t.Fatalf("written %q", got)
+	}
+
+	if err := doc.SetCell(3, 0, "x"); err != ErrRange {
+		t.Errorf("row out of range: err = %v", err)
+	}
+	if err := doc.SetCell(1, -1, "x"); err != ErrRange {
+		t.Errorf("col out of range: err = %v", err)
+	}
+}
+
+func TestSetCellGrowsShortRow(t *testing.T) {
+	doc := shortRowDocument()
+	if err := doc.SetCell(1, 2, "z"); err != nil {
+		t.Fatal(err)
+	}
+	checkRows(t, doc, [][]string{{"a", "b", "c"}, {"1", "", "z"}})
+}
+
+func TestSetRow(t *testing.T) {
+	doc := readSample(t)
+	if err := doc.SetRow(2, []string{"carl", "50", "extra"}); err != nil {
+		t.Fatal(err)
+	}
+	if doc.NumCols() != 3 {
+		t.Fatalf("NumCols = %d, want 3", doc.NumCols())
+	}
+	// Quoting of the fields that were quoted is kept
+	if got := writeString(t, doc); got != "name,age\r\nann,30\r\n\"carl\",50,extra\r\n" {
+		t.Fatalf("written %q", got)
+	}
+}
+
+// insertRowBelow: an empty row as wide as the header below the current row
+func TestInsertRow(t *testing.T) {
+	doc := readSample(t)
+	changes := record(doc)
+
+	if err := doc.InsertRow(2, make([]string, 2), nil); err != nil {
+		t.Fatal(err)
+	}
+	checkRows(t, doc, [][]string{{"name", "age"}, {"ann", "30"}, {"", ""}, {"bob, jr", "40"}})
+	if !reflect.DeepEqual(*changes, []Change{{Kind: RowInserted, Row: 2}}) {
+		t.Fatalf("changes = %+v", *changes)
+	}
+	if got := writeString(t, doc); got != "name,age\r\nann,30\r\n,\r\n\"bob, jr\",40\r\n" {
+		t.Fatalf("written %q", got)
+	}
+
+	// At the end
+	if err := doc.InsertRow(doc.NumRows(), []string{"dan", "1"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if doc.Cell(4, 0) != "dan" {
+		t.Fatalf("last row = %q", doc.Row(4))
+	}
+
+	if err := doc.InsertRow(0, nil, nil); err != ErrHeader {
+		t.Errorf("before the header: err = %v", err)
+	}
+	if err := doc.InsertRow(9, nil, nil); err != ErrRange {
+		t.Errorf("out of range: err = %v", err)
+	}
+}
+
+func TestInsertRowCopiesCells(t *testing.T) {
+	doc := New(csvio.DefaultDialect, []string{"a"}, nil)
+	cells := []string{"x"}
+	doc.InsertRow(1, cells, nil)
+	cells[0] = "changed"
+	if doc.Cell(1, 0) != "x" {
+		t.Fatal("the document shares the cells of InsertRow")
+	}
+}
+
+// deleteSelectedRow: any row but the header, InsertRow puts it back
+func TestDeleteRow(t *testing.T) {
+	doc := readSample(t)
+	changes := record(doc)
+
+	cells, format, err := doc.DeleteRow(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(cells, []string{"ann", "30"}) || format == nil {
+		t.Fatalf("deleted %q, format %v", cells, format)
+	}
+	checkRows(t, doc, [][]string{{"name", "age"}, {"bob, jr", "40"}})
+	if !reflect.DeepEqual(*changes, []Change{{Kind: RowDeleted, Row: 1}}) {
+		t.Fatalf("changes = %+v", *changes)
+	}
+
+	// Undo: the row comes back the way it was written
+	if err := doc.InsertRow(1, cells, format); err != nil {
+		t.Fatal(err)
+	}
+	if got := writeString(t, doc); got != sample {
+		t.Fatalf("written %q, want %q", got, sample)
+	}
+
+	if _, _, err := doc.DeleteRow(0); err != ErrHeader {
+		t.Errorf("header: err = %v", err)
+	}
+	if _, _, err := doc.DeleteRow(3); err != ErrRange {
+		t.Errorf("out of range: err = %v", err)
+	}
+}
+
+// insertColumnRight: an empty column right of the current one
+func TestInsertColumn(t *testing.T) {
+	doc := readSample(t)
+	changes := record(doc)
+
+	if err := doc.InsertColumn(1, make([]string, doc.NumRows())); err != nil {
+		t.Fatal(err)
+	}
+	checkRows(t, doc, [][]string{{"name", "", "age"}, {"ann", "", "30"}, {"bob, jr", "", "40"}})
+	if doc.NumCols() != 3 {
+		t.Fatalf("NumCols = %d", doc.NumCols())
+	}
+	if !reflect.DeepEqual(*changes, []Change{{Kind: ColumnInserted, Col: 1}}) {
+		t.Fatalf("changes = %+v", *changes)
+	}
+	// The quoted field stays quoted, the new one is not
+	if got := writeString(t, doc); got != "name,,age\r\nann,,30\r\n\"bob, jr\",,40\r\n" {
+		t.Fatalf("written %q", got)
+	}
+
+	if err := doc.InsertColumn(4, nil); err != ErrRange {
+		t.Errorf("out of range: err = %v", err)
+	}
+}
+
+func TestInsertColumnPadsShortRows(t *testing.T) {
+	doc := shortRowDocument()
+	if err := doc.InsertColumn(3, []string{"d", "4"}); err != nil {
+		t.Fatal(err)
+	}
+	checkRows(t, doc, [][]string{{"a", "b", "c", "d"}, {"1", "", "", "4"}})
+}
+
+// deleteSelectedCol: DeleteColumn returns the cells, InsertColumn puts them back
+func TestDeleteColumn(t *testing.T) {
+	doc := readSample(t)
+	changes := record(doc)
+
+	cells, err := doc.DeleteColumn(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(cells, []string{"name", "ann", "bob, jr"}) {
+		t.Fatalf("deleted %q", cells)
+	}
+	checkRows(t, doc, [][]string{{"age"}, {"30"}, {"40"}})
+	if doc.NumCols() != 1 {
+		t.Fatalf("NumCols = %d", doc.NumCols())
+	}
+	if !reflect.DeepEqual(*changes, []Change{{Kind: ColumnDeleted, Col: 0}}) {
+		t.Fatalf("changes = %+v", *changes)
+	}
+
+	if err := doc.InsertColumn(0, cells); err != nil {
+		t.Fatal(err)
+	}
+	if got := writeString(t, doc); got != sample {
+		t.Fatalf("written %q, want %q", got, sample)
+	}
+
+	if _, err := doc.DeleteColumn(2); err != ErrRange {
+		t.Errorf("out of range: err = %v", err)
+	}
+}
+
+func TestDeleteColumnShortRows(t *testing.T) {
+	doc := shortRowDocument()
+	cells, err := doc.DeleteColumn(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(cells, []string{"c", ""}) {
+		t.Fatalf("deleted %q", cells)
+	}
+	checkRows(t, doc, [][]string{{"a", "b"}, {"1"}})
+}
+
+func TestReorderRows(t *testing.T) {
+	doc := readString(t, "n\n1\n2\n3\n", csvio.DefaultDialect)
+	changes := record(doc)
+
+	if err := doc.ReorderRows(1, []int{2, 0, 1}); err != nil {
+		t.Fatal(err)
+	}
+	checkRows(t, doc, [][]string{{"n"}, {"3"}, {"1"}, {"2"}})
+	if !reflect.DeepEqual(*changes, []Change{{Kind: RowsReordered, Row: 1, Order: []int{2, 0, 1}}}) {
+		t.Fatalf("changes = %+v", *changes)
+	}
+	// Rows keep their format, so nothing but the order changes
+	if got := writeString(t, doc); got != "n\n3\n1\n2\n" {
+		t.Fatalf("written %q", got)
+	}
+
+	for _, order := range [][]int{{0, 0, 1}, {0, 1, 3}, {0, 1, 2, 3}} {
+		if err := doc.ReorderRows(1, order); err != ErrRange {
+			t.Errorf("order %v: err = %v", order, err)
+		}
+	}
+	if err := doc.ReorderRows(0, []int{1, 0}); err != ErrRange {
+		t.Errorf("header moved: err = %v", err)
+	}
+}
+
+func TestNoHeader(t *testing.T) {
+	d := csvio.DefaultDialect
+	d.HasHeader = false
+	doc := readString(t, "1,2\n3,4\n", d)
+	checkRows(t, doc, [][]string{{"A", "B"}, {"1", "2"}, {"3", "4"}})
+
+	if err := doc.SetCell(0, 0, "x"); err != ErrReadOnly {
+		t.Errorf("SetCell on the labels: err = %v", err)
+	}
+	if err := doc.SetRow(0, nil); err != ErrReadOnly {
+		t.Errorf("SetRow on the labels: err = %v", err)
+	}
+
+	// The labels follow the columns
+	doc.InsertColumn(2, nil)
+	if !reflect.DeepEqual(doc.Row(0), []string{"A", "B", "C"}) {
+		t.Fatalf("labels = %q", doc.Row(0))
+	}
+	doc.DeleteColumn(2)
+
+	doc.Label = ColumnNumbers
+	doc.Relabel()
+	if !reflect.DeepEqual(doc.Row(0), []string{"1", "2"}) {
+		t.Fatalf("labels = %q", doc.Row(0))
+	}
+
+	// Labels are not written
+	if got := writeString(t, doc); got != "1,2\n3,4\n" {
+		t.Fatalf("written %q", got)
+	}
+}
+
+func TestColumnLetters(t *testing.T) {
+	for c, want := range map[int]string{0: "A", 25: "Z", 26: "AA", 27: "AB", 701: "ZZ", 702: "AAA"} {
+		if got := ColumnLetters(c); got != want {
+			t.Errorf("ColumnLetters(%d) = %q, want %q", c, got, want)
+		}
+	}
+}
+
+// :header / :noheader
+func TestSetHeader(t *testing.T) {
+	doc := readSample(t)
+	changes := record(doc)
+
+	// Demote: the header moves into the data, labels instead
+	header, format := doc.Row(0), doc.Formats()[0]
+	doc.InsertRow(1, header, format)
+	doc.SetHeader(false, header, nil)
+	if doc.HasHeader() {
+		t.Fatal("HasHeader after SetHeader(false)")
+	}
+	checkRows(t, doc, [][]string{{"A", "B"}, {"name", "age"}, {"ann", "30"}, {"bob, jr", "40"}})
+	if got := writeString(t, doc); got != sample {
+		t.Fatalf("written %q, want %q", got, sample)
+	}
+
+	// Promote row 1 again
+	cells, format, _ := doc.DeleteRow(1)
+	doc.SetHeader(true, cells, format)
+	checkRows(t, doc, [][]string{{"name", "age"}, {"ann", "30"}, {"bob, jr", "40"}})
+	if got := writeString(t, doc); got != sample {
+		t.Fatalf("written %q, want %q", got, sample)
+	}
+
+	kinds := []ChangeKind{RowInserted, HeaderChanged, RowDeleted, HeaderChanged}
+	if len(*changes) != len(kinds) {
+		t.Fatalf("changes = %+v", *changes)
+	}
+	for i, c := range *changes {
+		if c.Kind != kinds[i] {
+			t.Errorf("change %d: kind %v, want %v", i, c.Kind, kinds[i])
+		}
+	}
+}
+
+func TestSetDialect(t *testing.T) {
+	doc := readSample(t)
+	changes := record(doc)
+
+	d := doc.Dialect()
+	if err := doc.SetDialect(d); err != nil || doc.Dirty() || len(*changes) != 0 {
+		t.Fatalf("same dialect: err = %v, dirty = %v", err, doc.Dirty())
+	}
+
+	d.Delimiter = ';'
+	d.HasHeader = false // kept, see SetHeader
+	if err := doc.SetDialect(d); err != nil {
+		t.Fatal(err)
+	}
+	if !doc.Dirty() || !doc.HasHeader() || doc.Dialect().Delimiter != ';' {
+		t.Fatalf("dirty = %v, dialect = %v", doc.Dirty(), doc.Dialect())
+	}
+	if !reflect.DeepEqual(*changes, []Change{{Kind: DialectChanged}}) {
+		t.Fatalf("changes = %+v", *changes)
+	}
+	// Every row is written with the new delimiter
+	if got := writeString(t, doc); got != "name;age\r\nann;30\r\n\"bob, jr\";40\r\n" {
+		t.Fatalf("written %q", got)
+	}
+
+	d.Quote = d.Delimiter
+	if err := doc.SetDialect(d); err == nil {
+		t.Error("invalid dialect accepted")
+	}
+}
+
+func TestAppendRows(t *testing.T) {
+	doc := New(csvio.DefaultDialect, []string{"a", "b"}, nil)
+	changes := record(doc)
+
+	doc.AppendRows([][]string{{"1", "2"}, {"3", "4", "5"}}, nil)
+	if doc.Dirty() {
+		t.Fatal("AppendRows made the document dirty")
+	}
+	if doc.NumRows() != 3 || doc.NumCols() != 3 {
+		t.Fatalf("size = %d x %d", doc.NumRows(), doc.NumCols())
+	}
+	if !reflect.DeepEqual(*changes, []Change{{Kind: RowsAppended, Row: 1}}) {
+		t.Fatalf("changes = %+v", *changes)
+	}
+}
+
+func TestEncoding(t *testing.T) {
+	d := csvio.DefaultDialect
+	d.Encoding = csvio.Latin1
+	doc := readString(t, "name\nJos\xe9\n", d)
+	if doc.Cell(1, 0) != "José" {
+		t.Fatalf("cell = %q", doc.Cell(1, 0))
+	}
+
+	doc.SetCell(1, 0, "José ✓")
+	if got := writeString(t, doc); got != "name\nJos\xe9 ?\n" {
+		t.Fatalf("written %q", got)
+	}
+	if n, row, col := doc.Lost(); n != 1 || row != 1 || col != 0 {
+		t.Fatalf("Lost() = %d, %d, %d", n, row, col)
+	}
+}
+
+func TestOpenSave(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "people.csv")
+	if err := os.WriteFile(path, []byte("name;age\nann;30\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	doc, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.Dialect().Delimiter != ';' || doc.Cell(1, 1) != "30" {
+		t.Fatalf("dialect %v, rows %q", doc.Dialect(), doc.Rows())
+	}
+
+	doc.SetCell(1, 1, "31")
+
+	// A copy leaves the document dirty
+	copyPath := filepath.Join(dir, "copy.csv")
+	if err := doc.WriteFile(copyPath); err != nil {
+		t.Fatal(err)
+	}
+	if !doc.Dirty() {
+		t.Fatal("WriteFile cleared Dirty")
+	}
+
+	if err := doc.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	if doc.Dirty() {
+		t.Fatal("Save left the document dirty")
+	}
+	for _, p := range []string{path, copyPath} {
+		got, _ := os.ReadFile(p)
+		if string(got) != "name;age\nann;31\n" {
+			t.Errorf("%s: %q", filepath.Base(p), got)
+		}
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Error("temp file left behind")
+	}
+
+	if _, err := Open(filepath.Join(dir, "missing.csv")); err == nil {
+		t.Error("Open of a missing file did not fail")
+	}
+}
@@ -0,0 +1,163 @@
+/**
+ * MIT License
+ *
+ * Copyright (c) 2025 Viki (VN - initials of my first and last name)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ *
+ * Contact: contact@viki.design
+ * Website: https://www.viki.design
+ *
+ */
+
+package document
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+
+	"neoviki_spreadsheet/modules_neoviki/csvio"
+)
+
+// Read reads a whole csv file written in dialect d, text in d.Encoding is
+// turned into UTF-8. Whether the file starts with a BOM and how it ends are
+// taken from the file.
+func Read(r io.Reader, d csvio.Dialect) (*Document, error) {
+	return read(csvio.NewDecoder(r, d.Encoding), d)
+}
+
+// Open reads a csv file, its encoding and dialect are guessed
+func Open(path string) (*Document, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	raw := bufio.NewReaderSize(f, csvio.SniffSize)
+	rawSample, _ := raw.Peek(csvio.SniffSize)
+	enc := csvio.DetectEncoding(rawSample)
+
+	// Everything after this point is UTF-8
+	br := bufio.NewReaderSize(csvio.NewDecoder(raw, enc), csvio.SniffSize)
+	sample, _ := br.Peek(csvio.SniffSize)
+	d := csvio.Sniff(sample)
+	d.Encoding = enc
+	return read(br, d)
+}
+
+// r is UTF-8 already
+func read(r io.Reader, d csvio.Dialect) (*Document, error) {
+	if err := d.Validate(); err != nil {
+		return nil, err
+	}
+	cr := csvio.NewReader(r, d)
+	header, format, err := cr.ReadRow()
+	if err == io.EOF {
+		// Empty file
+		header, format, err = []string{}, nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var rows [][]string
+	var formats []*csvio.RowFormat
+	for {
+		row, f, err := cr.ReadRow()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, row)
+		formats = append(formats, f)
+	}
+
+	d.BOM = cr.BOM()
+	d.Tail = cr.Tail()
+	doc := New(d, header, format)
+	doc.AppendRows(rows, formats)
+	return doc, nil
+}
+
+// Write writes the document in its dialect, the labels of a file without
+// header are left out. Characters the encoding can not store are written
+// as "?", see Lost.
+func (d *Document) Write(w io.Writer) error {
+	cw := csvio.NewWriter(w, d.dialect)
+	first := 0
+	if !d.dialect.HasHeader {
+		first = 1
+	}
+	for i := first; i < len(d.rows); i++ {
+		if err := cw.WriteRow(d.rows[i], d.formats[i]); err != nil {
+			return err
+		}
+	}
+	if err := cw.Finish(); err != nil {
+		return err
+	}
+	d.lost, d.lostRow, d.lostCol = cw.Lost()
+	d.lostRow += first
+	return nil
+}
+
+// Lost is the number of cells the last Write could not store completely,
+// row and col tell where the first one is
+func (d *Document) Lost() (n int, row int, col int) {
+	return d.lost, d.lostRow, d.lostCol
+}
+
+// WriteFile writes the document to path through a temporary file, so path
+// is never left half written. The document stays dirty ( a copy ).
+func (d *Document) WriteFile(path string) error {
+	tempFile := path + ".tmp"
+	f, err := os.Create(tempFile)
+	if err != nil {
+		return fmt.Errorf("creating temp CSV: %v", err)
+	}
+	err = d.Write(f)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tempFile)
+		return fmt.Errorf("writing CSV data: %v", err)
+	}
+	if err = os.Rename(tempFile, path); err != nil {
+		os.Remove(tempFile)
+		return fmt.Errorf("renaming temp file: %v", err)
+	}
+	return nil
+}
+
+// Save writes the document to path and marks it clean
+func (d *Document) Save(path string) error {
+	if err := d.WriteFile(path); err != nil {
+		return err
+	}
+	d.dirty = false
+	return nil
+}
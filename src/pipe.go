@@ -0,0 +1,140 @@
+/**
+ * MIT License
+ *
+ * Copyright (c) 2025 Viki (VN - initials of my first and last name)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ *
+ * Contact: contact@viki.design
+ * Website: https://www.viki.design
+ *
+ */
+
+package main
+
+/*
+  Pipelines:
+
+    csvgo - reads the csv from stdin. The UI runs on the terminal ( tcell
+    opens /dev/tty ), so stdin and stdout can both be pipes:
+
+        some-command | csvgo -stdout - | other-command
+
+    -stdout writes the result to stdout when csvgo exits and leaves the input
+    file alone. Ctrl+S / :w keep the current state as the result, quitting
+    without changes passes the input through. When unsaved changes are
+    discarded ( :q!, Discard & Quit ) the last saved state is written, or
+    nothing at all if nothing was saved: csvgo exits with status 1 then, so
+    the rest of the pipeline does not run on a result that was thrown away.
+
+    stdin has no config file, journal or .completed.csv file. Without -stdout
+    Ctrl+S asks for a file name.
+*/
+
+import (
+    "fmt"
+    "io"
+    "os"
+    "path/filepath"
+)
+
+const stdinPath = "-"
+
+var (
+    stdoutFlag bool
+
+    // Temp file with the result for stdout, "" until the first save
+    stdoutResult string
+)
+
+func readingStdin() bool {
+    return inputFile == stdinPath
+}
+
+// Journal and .completed.csv belong to a file that is saved in place
+func editingInPlace() bool {
+    return !readingStdin() && !stdoutFlag
+}
+
+// Name of the file in the status line
+func displayName() string {
+    if readingStdin() {
+        return "[stdin]"
+    }
+    return filepath.Base(inputFile)
+}
+
+// -stdout: Ctrl+S / :w keep the current state as the result
+func saveStdoutResult() bool {
+    if stdoutResult == "" {
+        f, err := os.CreateTemp("", "csvgo-*.csv")
+        if err != nil {
+            showMessage("Error: %v", err)
+            return false
+        }
+        f.Close()
+        stdoutResult = f.Name()
+    }
+
+    warning, err := writeCSVFile(stdoutResult)
+    if err != nil {
+        showMessage("Error: %v", err)
+        return false
+    }
+    markSaved()
+    showMessage("Saved, written to stdout on exit%s", warning)
+    return true
+}
+
+// Called when the UI is gone, returns the exit status
+func writeStdoutResult() int {
+    if !stdoutFlag {
+        return 0
+    }
+    if stdoutResult == "" {
+        switch {
+        case doc.Dirty():
+            fmt.Fprintln(os.Stderr, "csvgo: changes discarded, nothing written to stdout")
+            return 1
+        case loading || loadErr != nil:
+            fmt.Fprintln(os.Stderr, "csvgo: the input was not read completely, nothing written to stdout")
+            return 1
+        }
+        // Nothing changed, the input goes through as it was read
+        if !saveStdoutResult() {
+            fmt.Fprintf(os.Stderr, "csvgo: %s\n", statusMessage)
+            return 1
+        }
+    }
+    defer os.Remove(stdoutResult)
+
+    f, err := os.Open(stdoutResult)
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "csvgo: %v\n", err)
+        return 1
+    }
+    defer f.Close()
+    if _, err := io.Copy(os.Stdout, f); err != nil {
+        fmt.Fprintf(os.Stderr, "csvgo: writing stdout: %v\n", err)
+        return 1
+    }
+    return 0
+}
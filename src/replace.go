@@ -0,0 +1,390 @@
+/**
+ * MIT License
+ *
+ * Copyright (c) 2025 Viki (VN - initials of my first and last name)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ *
+ * Contact: contact@viki.design
+ * Website: https://www.viki.design
+ *
+ */
+
+package main
+
+/*
+  Find and replace:
+
+    `R` ( or :replace ) opens the replace dialog:
+
+        Find / Replace with   literal text, or a regular expression where the
+                              replacement can use capture groups ( $1, ${name} )
+        Scope                 current column, selected range or whole sheet
+                              ( column and sheet leave the header row alone )
+
+    The dialog shows how many cells / matches would change. "Replace all"
+    changes everything at once, "Confirm each" walks through the matches one
+    by one ( Replace / Skip / All / Stop ). Either way the result is one
+    change in the undo history.
+*/
+
+import (
+    "fmt"
+    "regexp"
+    "sort"
+    "strings"
+
+    "github.com/gdamore/tcell/v2"
+    "github.com/rivo/tview"
+)
+
+const (
+    replaceScopeColumn = iota
+    replaceScopeSelection
+    replaceScopeSheet
+)
+
+var replaceScopes = []string{"Current column", "Selection", "Whole sheet"}
+
+var (
+    // Remembered between two uses of the dialog
+    replaceFind       string
+    replaceWith       string
+    replaceRegexMode  bool
+    replaceIgnoreCase bool
+    replaceScope      = replaceScopeColumn
+)
+
+// One match that may be replaced
+type replaceMatch struct {
+    row   int
+    col   int
+    start int
+    end   int
+
+    // Text that replaces text[start:end]
+    replacement string
+}
+
+func compileReplace() (*regexp.Regexp, error) {
+    if replaceFind == "" {
+        return nil, fmt.Errorf("nothing to find")
+    }
+    expr := replaceFind
+    if !replaceRegexMode {
+        expr = regexp.QuoteMeta(expr)
+    }
+    if replaceIgnoreCase {
+        expr = "(?i)" + expr
+    }
+    return regexp.Compile(expr)
+}
+
+// Call fn for every cell in the scope, row by row
+func forEachReplaceCell(fn func(row int, col int)) {
+    top, left, bottom, right := 1, 0, len(data)-1, numCols-1
+
+    switch replaceScope {
+    case replaceScopeColumn:
+        left, right = selectedCol, selectedCol
+    case replaceScopeSelection:
+        top, left, bottom, right = selectionBounds()
+    }
+    if top == 0 && !doc.HasHeader() {
+        // Column labels are not data ( see header.go )
+        top = 1
+    }
+
+    for r := top; r <= bottom; r++ {
+        // Rows hidden by the filter are left alone
+        if !rowVisible(r) {
+            continue
+        }
+        for c := left; c <= right; c++ {
+            fn(r, c)
+        }
+    }
+}
+
+// Every match in the scope, in sheet order
+func findReplaceMatches(re *regexp.Regexp) []replaceMatch {
+    var matches []replaceMatch
+
+    forEachReplaceCell(func(row int, col int) {
+        text := cellText(row, col)
+        for _, m := range re.FindAllStringSubmatchIndex(text, -1) {
+            if m[0] == m[1] && text != "" {
+                // Empty matches ( e.g. "x*" ) would replace between every
+                // character, only an empty cell is matched by one ( "^$" )
+                continue
+            }
+            replacement := replaceWith
+            if replaceRegexMode {
+                replacement = string(re.ExpandString(nil, replaceWith, text, m))
+            }
+            matches = append(matches, replaceMatch{row: row, col: col, start: m[0], end: m[1], replacement: replacement})
+        }
+    })
+    return matches
+}
+
+// Turn the accepted matches into one undoable change
+// Returns the number of replaced matches and changed cells and a warning when
+// the new text does not fit the file encoding, false when the change was refused
+func applyReplaceMatches(matches []replaceMatch) (int, int, string, bool) {
+    type cellPos struct{ row, col int }
+
+    byCell := make(map[cellPos][]replaceMatch)
+    var cells []cellPos
+    for _, m := range matches {
+        pos := cellPos{m.row, m.col}
+        if _, ok := byCell[pos]; !ok {
+            cells = append(cells, pos)
+        }
+        byCell[pos] = append(byCell[pos], m)
+    }
+
+    var ops batchOp
+    var added strings.Builder
+    replaced := 0
+    for _, pos := range cells {
+        old := cellText(pos.row, pos.col)
+        cellMatches := byCell[pos]
+        sort.Slice(cellMatches, func(i, j int) bool { return cellMatches[i].start < cellMatches[j].start })
+
+        var b strings.Builder
+        last := 0
+        for _, m := range cellMatches {
+            b.WriteString(old[last:m.start])
+            b.WriteString(m.replacement)
+            added.WriteString(m.replacement)
+            last = m.end
+        }
+        b.WriteString(old[last:])
+
+        if b.String() != old {
+            ops = append(ops, &setCellOp{row: pos.row, col: pos.col, oldText: old, newText: b.String()})
+            replaced += len(cellMatches)
+        }
+    }
+
+    if len(ops) > 0 && !runOp(ops, selectedRow, selectedCol) {
+        return 0, 0, "", false
+    }
+
+    warning := unrepresentableWarning(added.String())
+    if warning != "" {
+        warning = ", warning: " + warning
+    }
+    return replaced, len(ops), warning, true
+}
+
+func replacePreview() string {
+    re, err := compileReplace()
+    if err != nil {
+        return err.Error()
+    }
+    matches := findReplaceMatches(re)
+
+    cells := make(map[[2]int]bool)
+    for _, m := range matches {
+        cells[[2]int{m.row, m.col}] = true
+    }
+    return fmt.Sprintf("%d match(es) in %d cell(s)", len(matches), len(cells))
+}
+
+func openReplaceDialog() {
+    if journalBusy() {
+        return
+    }
+    // A range selected with Shift+arrows is what the user most likely wants to change
+    if rangeActive {
+        replaceScope = replaceScopeSelection
+    } else if replaceScope == replaceScopeSelection {
+        replaceScope = replaceScopeColumn
+    }
+
+    form := tview.NewForm()
+    preview := tview.NewTextView()
+    preview.SetLabel("Preview")
+    // One line, a text view without a size takes the rest of the form ( and the buttons' place )
+    preview.SetSize(1, 0)
+
+    updatePreview := func() {
+        preview.SetText(replacePreview())
+    }
+
+    closeDialog := func() {
+        pages.RemovePage("replace")
+        app.SetFocus(table)
+    }
+
+    form.AddInputField("Find", replaceFind, 30, nil, func(text string) {
+        replaceFind = text
+        updatePreview()
+    })
+    form.AddInputField("Replace with", replaceWith, 30, nil, func(text string) {
+        replaceWith = text
+        updatePreview()
+    })
+    form.AddCheckbox("Regex ( $1 in replacement )", replaceRegexMode, func(checked bool) {
+        replaceRegexMode = checked
+        updatePreview()
+    })
+    form.AddCheckbox("Ignore case", replaceIgnoreCase, func(checked bool) {
+        replaceIgnoreCase = checked
+        updatePreview()
+    })
+    form.AddDropDown("Scope", replaceScopes, replaceScope, func(option string, index int) {
+        replaceScope = index
+        updatePreview()
+    })
+    form.AddFormItem(preview)
+
+    form.AddButton("Replace all", func() {
+        re, err := compileReplace()
+        if err != nil {
+            showMessage("Error: %v", err)
+            return
+        }
+        closeDialog()
+        replaced, n, warning, ok := applyReplaceMatches(findReplaceMatches(re))
+        if ok {
+            showMessage("Replaced %d match(es) in %d cell(s)%s", replaced, n, warning)
+        }
+    })
+    form.AddButton("Confirm each", func() {
+        re, err := compileReplace()
+        if err != nil {
+            showMessage("Error: %v", err)
+            return
+        }
+        closeDialog()
+        confirmReplaceMatches(findReplaceMatches(re))
+    })
+    form.AddButton("Cancel", closeDialog)
+    form.SetCancelFunc(closeDialog)
+
+    form.SetBorder(true).SetTitle(" Find and replace ( Esc: cancel ) ")
+    updatePreview()
+
+    modal := tview.NewFlex().
+        AddItem(nil, 0, 1, false).
+        AddItem(tview.NewFlex().
+            SetDirection(tview.FlexRow).
+            AddItem(nil, 0, 1, false).
+            AddItem(form, 17, 0, true).
+            AddItem(nil, 0, 1, false), 70, 0, true).
+        AddItem(nil, 0, 1, false)
+
+    pages.AddPage("replace", modal, true, true)
+    app.SetFocus(form)
+}
+
+// Ask Replace / Skip / All / Stop for every match, then apply the accepted ones
+func confirmReplaceMatches(matches []replaceMatch) {
+    if len(matches) == 0 {
+        showMessage("No matches")
+        return
+    }
+
+    var accepted []replaceMatch
+    index := 0
+
+    finish := func() {
+        pages.RemovePage("confirm")
+        replaced, n, warning, ok := applyReplaceMatches(accepted)
+        if ok {
+            showMessage("Replaced %d of %d match(es) in %d cell(s)%s", replaced, len(matches), n, warning)
+        }
+    }
+
+    var askNext func()
+    askNext = func() {
+        if index >= len(matches) {
+            finish()
+            return
+        }
+        m := matches[index]
+
+        selectedRow = m.row
+        selectedCol = m.col
+        refreshTable()
+
+        text := cellText(m.row, m.col)
+        before := text[:m.start]
+        if len([]rune(before)) > 20 {
+            before = "..." + string([]rune(before)[len([]rune(before))-20:])
+        }
+        after := text[m.end:]
+        if len([]rune(after)) > 20 {
+            after = string([]rune(after)[:20]) + "..."
+        }
+
+        question := fmt.Sprintf("Match %d of %d ( row %d, col %d )\n\n%s«%s»%s\n\nreplace with «%s»?",
+            index+1, len(matches), m.row, m.col+1, tview.Escape(before), tview.Escape(text[m.start:m.end]), tview.Escape(after), tview.Escape(m.replacement))
+
+        modal := tview.NewModal().
+            SetText(question).
+            AddButtons([]string{"Replace", "Skip", "All", "Stop"}).
+            SetButtonBackgroundColor(tcell.ColorDarkCyan).
+            SetButtonStyle(tcell.StyleDefault.
+                Foreground(tcell.ColorWhite).
+                Background(tcell.ColorDarkCyan)).
+            SetButtonActivatedStyle(tcell.StyleDefault.
+                Foreground(tcell.ColorYellow).
+                Background(tcell.ColorDarkCyan).
+                Bold(true)).
+            SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+                pages.RemovePage("confirm")
+                switch buttonLabel {
+                case "Replace":
+                    accepted = append(accepted, m)
+                    index++
+                    askNext()
+                case "Skip":
+                    index++
+                    askNext()
+                case "All":
+                    accepted = append(accepted, matches[index:]...)
+                    index = len(matches)
+                    finish()
+                default:
+                    finish()
+                }
+            })
+
+        pages.AddPage("confirm", modal, true, true)
+    }
+
+    askNext()
+}
+
+func registerReplaceCommands() {
+    registerCommand(&command{
+        name: "replace",
+        help: "Open the find and replace dialog",
+        run: func(bang bool, args string) error {
+            openReplaceDialog()
+            return nil
+        },
+    })
+}
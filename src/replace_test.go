@@ -0,0 +1,86 @@
+/**
+ * MIT License
+ *
+ * Copyright (c) 2025 Viki (VN - initials of my first and last name)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ *
+ * Contact: contact@viki.design
+ * Website: https://www.viki.design
+ *
+ */
+
+package main
+import (
+    "fmt"
+    "reflect"
+    "testing"
+)
+
+func TestFindReplaceMatches(t *testing.T) {
+    useTestData(t, [][]string{
+        {"name", "date", "note"},
+        {"Ann Lee", "2025-10-01", ""},
+        {"bob", "1.2.2025", "a+b"},
+    })
+    t.Cleanup(func() { replaceFind, replaceWith, replaceRegexMode, replaceIgnoreCase = "", "", false, false })
+    replaceScope = replaceScopeSheet
+
+    // Matches as "row,col start-end replacement"
+    tests := []struct {
+        find, with        string
+        regex, ignoreCase bool
+        want              []string
+    }{
+        {"a", "4", false, false, []string{"2,2 0-1 4"}},
+        {"a", "4", false, true, []string{"1,0 0-1 4", "2,2 0-1 4"}},
+        // Literal text: no pattern, no groups
+        {"a+b", "$1", false, false, []string{"2,2 0-3 $1"}},
+        {`(\w+) (\w+)`, "$2, $1", true, false, []string{"1,0 0-7 Lee, Ann"}},
+        {`(?P<y>\d{4})-(?P<m>\d\d)-(?P<d>\d\d)`, "${d}.${m}.${y}", true, false, []string{"1,1 0-10 01.10.2025"}},
+        // $1x is the group named "1x", ${1}x is group 1 and an x
+        {`(b)`, "${1}x", true, false, []string{"2,0 0-1 bx", "2,0 2-3 bx", "2,2 2-3 bx"}},
+        {`(b)`, "$1x", true, false, []string{"2,0 0-1 ", "2,0 2-3 ", "2,2 2-3 "}},
+        // Empty matches only in empty cells
+        {`^$`, "n/a", true, false, []string{"1,2 0-0 n/a"}},
+        {`x*`, "-", true, false, []string{"1,2 0-0 -"}},
+    }
+    for _, tt := range tests {
+        replaceFind, replaceWith, replaceRegexMode, replaceIgnoreCase = tt.find, tt.with, tt.regex, tt.ignoreCase
+        re, err := compileReplace()
+        if err != nil {
+            t.Errorf("compileReplace(%q): %v", tt.find, err)
+            continue
+        }
+        var got []string
+        for _, m := range findReplaceMatches(re) {
+            got = append(got, fmt.Sprintf("%d,%d %d-%d %s", m.row, m.col, m.start, m.end, m.replacement))
+        }
+        if !reflect.DeepEqual(got, tt.want) {
+            t.Errorf("%q -> %q regex=%v: %q, want %q", tt.find, tt.with, tt.regex, got, tt.want)
+        }
+    }
+
+    replaceFind = ""
+    if _, err := compileReplace(); err == nil {
+        t.Error("compileReplace with nothing to find gave no error")
+    }
+}
@@ -0,0 +1,53 @@
+/**
+ * MIT License
+ *
+ * Copyright (c) 2025 Viki (VN - initials of my first and last name)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ *
+ * Contact: contact@viki.design
+ * Website: https://www.viki.design
+ *
+ */
+
+
+package main
+
+/*
+  Lossless save:
+
+    The reader keeps for every row how it was written in the file ( its raw
+    text, line ending and which fields were quoted, see csvio.RowFormat ).
+    On save a row that was not changed is written back byte for byte, a
+    changed row is written from its fields with the same quoting and line
+    ending. BOM, line endings and the final newline are part of the dialect,
+    so opening and saving a file without edits does not change a single byte
+    and diffs only show the rows that were edited. The document ( see
+    document.go ) drops the raw text of a row when the row changes.
+*/
+
+import (
+    "neoviki_spreadsheet/modules_neoviki/csvio"
+)
+
+// One entry per row of `data`, nil for rows that were not read from the file
+// ( kept in step with the document, see document.go )
+var rowFormats []*csvio.RowFormat
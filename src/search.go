@@ -0,0 +1,403 @@
+/**
+ * MIT License
+ *
+ * Copyright (c) 2025 Viki (VN - initials of my first and last name)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ *
+ * Contact: contact@viki.design
+ * Website: https://www.viki.design
+ *
+ */
+
+package main
+
+/*
+  Search:
+
+    `/` opens the search prompt. Every cell that matches is highlighted while
+    typing and the cursor jumps to the first hit after the cursor ( Enter keeps
+    it there, Esc goes back ). After a search:
+
+        n / N     next / previous hit
+        Ctrl+F    popup with every hit, Enter jumps to the selected one
+        Esc       clears the search ( n clears cells again )
+
+    Modes, toggled in the prompt with Alt+c / Alt+w / Alt+r or with :set
+        ignorecase  case insensitive ( default )
+        wholecell   the whole cell has to match
+        regex       the pattern is a regular expression
+*/
+
+import (
+    "fmt"
+    "regexp"
+    "strings"
+
+    "github.com/gdamore/tcell/v2"
+    "github.com/rivo/tview"
+)
+
+// Max number of hits listed in the popup
+const maxSearchHits = 10000
+
+var (
+    searchField   *tview.InputField
+    searchPattern string
+    searchActive  bool
+
+    searchIgnoreCase = true
+    searchWholeCell  = false
+    searchRegex      = false
+
+    // Compiled from pattern + modes, nil when there is nothing to match
+    searchMatcher func(string) bool
+
+    // Cursor when the prompt was opened ( Esc goes back there )
+    searchStartRow int
+    searchStartCol int
+)
+
+func searchInit() {
+    searchField = tview.NewInputField()
+    updateSearchLabel()
+
+    searchField.SetChangedFunc(func(text string) {
+        searchPattern = text
+        compileSearch()
+        if searchMatcher != nil {
+            if row, col, ok := findHit(searchStartRow, searchStartCol, true, true); ok {
+                selectedRow = row
+                selectedCol = col
+            }
+        }
+        refreshSearchView()
+    })
+
+    searchField.SetDoneFunc(func(key tcell.Key) {
+        if key == tcell.KeyTab || key == tcell.KeyBacktab {
+            return
+        }
+        closeSearch()
+
+        if key == tcell.KeyEnter && searchMatcher != nil {
+            searchActive = true
+            showMessage("%d hit(s) for %q", countHits(), searchPattern)
+        } else {
+            // Cancelled: back to where the search started
+            clearSearch()
+            selectedRow = searchStartRow
+            selectedCol = searchStartCol
+        }
+        refreshTable()
+    })
+
+    searchField.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+        if event.Key() == tcell.KeyCtrlF {
+            if searchMatcher != nil {
+                closeSearch()
+                searchActive = true
+                showSearchHits()
+            }
+            return nil
+        }
+        if event.Key() != tcell.KeyRune || event.Modifiers()&tcell.ModAlt == 0 {
+            return event
+        }
+        switch event.Rune() {
+        case 'c':
+            searchIgnoreCase = !searchIgnoreCase
+        case 'w':
+            searchWholeCell = !searchWholeCell
+        case 'r':
+            searchRegex = !searchRegex
+        default:
+            return event
+        }
+        updateSearchLabel()
+        compileSearch()
+        refreshSearchView()
+        return nil
+    })
+
+    registerSearchCommands()
+}
+
+func updateSearchLabel() {
+    var modes []string
+    if !searchIgnoreCase {
+        modes = append(modes, "case")
+    }
+    if searchWholeCell {
+        modes = append(modes, "whole")
+    }
+    if searchRegex {
+        modes = append(modes, "regex")
+    }
+
+    label := "/"
+    if len(modes) > 0 {
+        label = "(" + strings.Join(modes, ",") + ") /"
+    }
+    searchField.SetLabel(label)
+}
+
+func openSearch() {
+    editing = true
+    searchStartRow = selectedRow
+    searchStartCol = selectedCol
+    searchField.SetText("")
+    searchPattern = ""
+    searchMatcher = nil
+    flex.AddItem(searchField, 3, 0, true)
+    app.SetFocus(searchField)
+}
+
+func closeSearch() {
+    editing = false
+    flex.RemoveItem(searchField)
+    app.SetFocus(table)
+}
+
+func clearSearch() {
+    searchActive = false
+    searchPattern = ""
+    searchMatcher = nil
+}
+
+// Build searchMatcher from the pattern and the modes
+func compileSearch() {
+    searchMatcher = nil
+    if searchPattern == "" {
+        return
+    }
+
+    if searchRegex {
+        expr := searchPattern
+        if searchWholeCell {
+            expr = "^(?:" + expr + ")$"
+        }
+        if searchIgnoreCase {
+            expr = "(?i)" + expr
+        }
+        re, err := regexp.Compile(expr)
+        if err != nil {
+            // Probably not typed completely yet
+            showMessage("Regex: %v", err)
+            return
+        }
+        clearMessage()
+        searchMatcher = re.MatchString
+        return
+    }
+
+    pattern := searchPattern
+    switch {
+    case searchIgnoreCase && searchWholeCell:
+        searchMatcher = func(text string) bool { return strings.EqualFold(text, pattern) }
+    case searchIgnoreCase:
+        lower := strings.ToLower(pattern)
+        searchMatcher = func(text string) bool { return strings.Contains(strings.ToLower(text), lower) }
+    case searchWholeCell:
+        searchMatcher = func(text string) bool { return text == pattern }
+    default:
+        searchMatcher = func(text string) bool { return strings.Contains(text, pattern) }
+    }
+}
+
+// Used by tableContent to highlight hits
+func isSearchHit(row int, col int) bool {
+    return searchMatcher != nil && searchMatcher(cellText(row, col))
+}
+
+// Walk the sheet row by row from (row, col) and wrap around at the end.
+// inclusive: (row, col) itself counts as a hit.
+func findHit(row int, col int, forward bool, inclusive bool) (int, int, bool) {
+    if searchMatcher == nil || len(data) == 0 {
+        return 0, 0, false
+    }
+
+    total := len(data) * numCols
+    pos := row*numCols + col
+    for i := 0; i < total; i++ {
+        step := i
+        if !inclusive {
+            step = i + 1
+        }
+        p := pos + step
+        if !forward {
+            p = pos - step
+        }
+        p = ((p % total) + total) % total
+
+        r, c := p/numCols, p%numCols
+        if rowVisible(r) && searchMatcher(cellText(r, c)) {
+            return r, c, true
+        }
+    }
+    return 0, 0, false
+}
+
+func countHits() int {
+    n := 0
+    for r := range data {
+        if !rowVisible(r) {
+            continue
+        }
+        for c := 0; c < numCols; c++ {
+            if searchMatcher(cellText(r, c)) {
+                n++
+            }
+        }
+    }
+    return n
+}
+
+func searchNext(forward bool) {
+    row, col, ok := findHit(selectedRow, selectedCol, forward, false)
+    if !ok {
+        showMessage("Pattern not found: %s", searchPattern)
+        return
+    }
+    if forward && (row < selectedRow || row == selectedRow && col <= selectedCol) {
+        showMessage("Search hit BOTTOM, continuing at TOP")
+    } else if !forward && (row > selectedRow || row == selectedRow && col >= selectedCol) {
+        showMessage("Search hit TOP, continuing at BOTTOM")
+    }
+    selectedRow = row
+    selectedCol = col
+    refreshTable()
+}
+
+// Redraw after the pattern / modes changed while the prompt is open
+func refreshSearchView() {
+    table.Select(viewRowOf(selectedRow), selectedCol)
+    updateStatusBar()
+}
+
+// Popup listing every hit
+func showSearchHits() {
+    list := tview.NewList().ShowSecondaryText(false)
+    list.SetBorder(true)
+
+    type hit struct{ row, col int }
+    var hits []hit
+
+    for r := range data {
+        if !rowVisible(r) {
+            continue
+        }
+        for c := 0; c < numCols && len(hits) < maxSearchHits; c++ {
+            if !searchMatcher(cellText(r, c)) {
+                continue
+            }
+            hits = append(hits, hit{r, c})
+            text := strings.ReplaceAll(cellText(r, c), "\n", " ")
+            list.AddItem(fmt.Sprintf("row %-6d %-12s %s", r, wrapText(cellText(0, c), 12), tview.Escape(text)), "", 0, nil)
+        }
+    }
+
+    title := fmt.Sprintf(" %d hit(s) for %q ( Enter: jump, Esc: close ) ", len(hits), searchPattern)
+    if len(hits) >= maxSearchHits {
+        title = fmt.Sprintf(" first %d hits for %q ( Enter: jump, Esc: close ) ", len(hits), searchPattern)
+    }
+    list.SetTitle(title)
+
+    closeHits := func() {
+        pages.RemovePage("search")
+        app.SetFocus(table)
+    }
+
+    list.SetSelectedFunc(func(index int, mainText string, secondaryText string, shortcut rune) {
+        closeHits()
+        selectedRow = hits[index].row
+        selectedCol = hits[index].col
+        refreshTable()
+    })
+    list.SetDoneFunc(closeHits)
+
+    modal := tview.NewFlex().
+        SetDirection(tview.FlexRow).
+        AddItem(nil, 2, 0, false).
+        AddItem(
+            tview.NewFlex().
+                AddItem(nil, 4, 0, false).
+                AddItem(list, 0, 1, true).
+                AddItem(nil, 4, 0, false),
+            0, 1, true).
+        AddItem(nil, 2, 0, false)
+
+    pages.AddPage("search", modal, true, true)
+    app.SetFocus(list)
+}
+
+func registerSearchCommands() {
+    registerCommand(&command{
+        name: "search", args: "<pattern>",
+        help: "Search for pattern ( same as / )",
+        run: func(bang bool, args string) error {
+            searchPattern = args
+            compileSearch()
+            if searchMatcher == nil {
+                return fmt.Errorf("nothing to search for")
+            }
+            searchActive = true
+            searchNext(true)
+            return nil
+        },
+    })
+    registerCommand(&command{
+        name: "hits",
+        help: "List every hit of the last search",
+        run: func(bang bool, args string) error {
+            if searchMatcher == nil {
+                return fmt.Errorf("no search")
+            }
+            showSearchHits()
+            return nil
+        },
+    })
+    registerCommand(&command{
+        name: "nohlsearch", aliases: []string{"noh"},
+        help: "Clear the search highlighting",
+        run: func(bang bool, args string) error {
+            clearSearch()
+            refreshTable()
+            return nil
+        },
+    })
+
+    boolOption := func(name string, help string, value *bool) {
+        registerOption(&option{
+            name: name, help: help, boolean: true,
+            get: func() string { return fmt.Sprint(*value) },
+            set: func(v string) error {
+                *value = v == "true" || v == "1" || v == "on"
+                updateSearchLabel()
+                compileSearch()
+                return nil
+            },
+        })
+    }
+    boolOption("ignorecase", "Search ignores case", &searchIgnoreCase)
+    boolOption("wholecell", "Search matches whole cells only", &searchWholeCell)
+    boolOption("regex", "Search pattern is a regular expression", &searchRegex)
+}
@@ -0,0 +1,76 @@
+/**
+ * MIT License
+ *
+ * Copyright (c) 2025 Viki (VN - initials of my first and last name)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ *
+ * Contact: contact@viki.design
+ * Website: https://www.viki.design
+ *
+ */
+
+package main
+import (
+    "reflect"
+    "testing"
+)
+
+func TestCompileSearch(t *testing.T) {
+    texts := []string{"Rome", "rome", "Jerome", "ROMEO", "R.me", ""}
+    tests := []struct {
+        pattern                  string
+        regex, whole, ignoreCase bool
+        want                     []string
+    }{
+        {"rome", false, false, true, []string{"Rome", "rome", "Jerome", "ROMEO"}},
+        {"rome", false, false, false, []string{"rome", "Jerome"}},
+        {"rome", false, true, true, []string{"Rome", "rome"}},
+        {"Rome", false, true, false, []string{"Rome"}},
+        // Literal: the dot is a dot
+        {"r.me", false, false, true, []string{"R.me"}},
+        {"r.me", true, false, true, []string{"Rome", "rome", "Jerome", "ROMEO", "R.me"}},
+        {"^r", true, false, false, []string{"rome"}},
+        {"rome|r.me", true, true, true, []string{"Rome", "rome", "R.me"}},
+        {"ROME", true, false, false, []string{"ROMEO"}},
+    }
+    for _, tt := range tests {
+        searchPattern, searchRegex, searchWholeCell, searchIgnoreCase = tt.pattern, tt.regex, tt.whole, tt.ignoreCase
+        compileSearch()
+        var got []string
+        for _, text := range texts {
+            if searchMatcher(text) {
+                got = append(got, text)
+            }
+        }
+        if !reflect.DeepEqual(got, tt.want) {
+            t.Errorf("%q regex=%v whole=%v ignorecase=%v matches %q, want %q", tt.pattern, tt.regex, tt.whole, tt.ignoreCase, got, tt.want)
+        }
+    }
+
+    // Not typed completely yet: no matcher
+    searchPattern, searchRegex = "(ro", true
+    compileSearch()
+    if searchMatcher != nil {
+        t.Errorf("%q compiled", searchPattern)
+    }
+    searchPattern, searchRegex, searchMatcher = "", false, nil
+}
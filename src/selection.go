@@ -0,0 +1,405 @@
+/**
+ * MIT License
+ *
+ * Copyright (c) 2025 Viki (VN - initials of my first and last name)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ *
+ * Contact: contact@viki.design
+ * Website: https://www.viki.design
+ *
+ */
+
+package main
+
+/*
+  Range selection:
+
+    Shift + arrow keys select a block of cells starting at the cursor ( the
+    anchor stays where the first Shift + arrow was pressed ). V selects whole
+    rows and | whole columns, the arrow keys then grow the selection without
+    Shift ( like vim's visual mode, V or | again ends it ). An arrow key
+    without Shift or Esc ends the selection.
+
+    The cell keys work on the whole selection: c copies it as tab separated
+    text ( rows on lines, cells with tabs or line breaks in "quotes" like
+    other spreadsheets ), x cuts, n / Delete clear it. v pastes such text as a
+    block at the cursor ( or the top left of the selection ), rows and columns
+    are added when the block does not fit. d deletes the selected rows and
+    Backspace the selected columns. Every one of them is one undo step.
+
+    Commands that work on "the selection" ( replace, sort ) use
+    selectionBounds(), which is the cursor cell when there is no range
+    selected. Rows hidden by the filter are left out.
+*/
+
+import (
+    "encoding/csv"
+    "fmt"
+    "strings"
+)
+
+// What the range selection covers
+const (
+    selectCells = iota
+    selectRows
+    selectColumns
+)
+
+var (
+    rangeActive    bool
+    rangeAnchorRow int
+    rangeAnchorCol int
+    rangeKind      int
+
+    // Started with V / |, the cursor keys grow the selection
+    visualMode bool
+)
+
+// Called before the cursor is moved, extend is set for the select_* actions
+// ( Shift + arrow keys )
+func trackRangeSelection(extend bool) {
+    if extend || visualMode {
+        if !rangeActive {
+            rangeActive = true
+            rangeKind = selectCells
+            rangeAnchorRow = selectedRow
+            rangeAnchorCol = selectedCol
+        }
+        return
+    }
+    rangeActive = false
+}
+
+func clearRangeSelection() {
+    rangeActive = false
+    visualMode = false
+}
+
+// V / |: select whole rows or columns, again ends the selection
+func toggleVisualSelection(kind int) {
+    if rangeActive && visualMode && rangeKind == kind {
+        clearRangeSelection()
+        refreshTable()
+        return
+    }
+    if !rangeActive {
+        rangeAnchorRow = selectedRow
+        rangeAnchorCol = selectedCol
+    }
+    rangeActive, visualMode, rangeKind = true, true, kind
+    refreshTable()
+}
+
+// Selected block ( inclusive ), the cursor cell when nothing is selected
+func selectionBounds() (top int, left int, bottom int, right int) {
+    if !rangeActive {
+        return selectedRow, selectedCol, selectedRow, selectedCol
+    }
+    top, bottom = rangeAnchorRow, selectedRow
+    if top > bottom {
+        top, bottom = bottom, top
+    }
+    left, right = rangeAnchorCol, selectedCol
+    if left > right {
+        left, right = right, left
+    }
+    switch rangeKind {
+    case selectRows:
+        left, right = 0, numCols-1
+    case selectColumns:
+        top, bottom = 0, len(data)-1
+    }
+
+    // Rows / columns may have been deleted since the anchor was set
+    if bottom >= len(data) {
+        bottom = len(data) - 1
+    }
+    if right >= numCols {
+        right = numCols - 1
+    }
+    return top, left, bottom, right
+}
+
+// Used by tableContent to highlight the selected block
+func inRangeSelection(row int, col int) bool {
+    if !rangeActive {
+        return false
+    }
+    top, left, bottom, right := selectionBounds()
+    return row >= top && row <= bottom && col >= left && col <= right
+}
+
+// Shown in the status bar while a range is selected
+func selectionStatus() string {
+    _, left, _, right := selectionBounds()
+    switch rangeKind {
+    case selectRows:
+        return fmt.Sprintf("%d row(s) selected", len(selectedRows()))
+    case selectColumns:
+        return fmt.Sprintf("%d column(s) selected", right-left+1)
+    }
+    return fmt.Sprintf("%d x %d cells selected", len(selectedRows()), right-left+1)
+}
+
+// Data rows of the selection that the filter shows
+func selectedRows() []int {
+    top, _, bottom, _ := selectionBounds()
+    var rows []int
+    for r := top; r <= bottom; r++ {
+        if rowVisible(r) {
+            rows = append(rows, r)
+        }
+    }
+    return rows
+}
+
+// A cell as tab separated text, quoted when it has a tab or a line break
+func tsvField(text string) string {
+    if !strings.ContainsAny(text, "\t\r\n") && !strings.HasPrefix(text, `"`) {
+        return text
+    }
+    return `"` + strings.ReplaceAll(text, `"`, `""`) + `"`
+}
+
+// The selection ( or the cursor cell ) as tab separated text
+func selectionTSV() string {
+    _, left, _, right := selectionBounds()
+    var lines []string
+    for _, r := range selectedRows() {
+        var fields []string
+        for c := left; c <= right; c++ {
+            fields = append(fields, tsvField(cellText(r, c)))
+        }
+        lines = append(lines, strings.Join(fields, "\t"))
+    }
+    // A single cell goes to the clipboard as it is, unless it would be read
+    // back as several cells
+    if len(lines) == 1 && left == right {
+        if text := cellText(selectedRows()[0], left); !strings.ContainsAny(text, "\t\r\n") {
+            return text
+        }
+    }
+    return strings.Join(lines, "\n")
+}
+
+// Clipboard text as a block of cells: lines are rows, tabs separate cells
+func parseTSV(text string) [][]string {
+    text = strings.TrimSuffix(strings.TrimSuffix(text, "\n"), "\r")
+    if !strings.ContainsAny(text, "\t\r\n") {
+        return [][]string{{text}}
+    }
+    r := csv.NewReader(strings.NewReader(text))
+    r.Comma = '\t'
+    r.LazyQuotes = true
+    r.FieldsPerRecord = -1
+    block, err := r.ReadAll()
+    if err == nil {
+        return block
+    }
+    // Not like a spreadsheet wrote it, take lines and tabs as they are
+    block = nil
+    for _, line := range strings.Split(text, "\n") {
+        block = append(block, strings.Split(strings.TrimSuffix(line, "\r"), "\t"))
+    }
+    return block
+}
+
+// c: copy the selection ( or the cursor cell ) to the clipboard
+func copySelection() bool {
+    if len(data) == 0 || selectedCol >= numCols || len(selectedRows()) == 0 {
+        return false
+    }
+    if err := clipboardWrite(selectionTSV()); err != nil {
+        showMessage("Clipboard write failed: %v", err)
+        return false
+    }
+    if rangeActive {
+        _, left, _, right := selectionBounds()
+        showMessage("Copied %d x %d cells", len(selectedRows()), right-left+1)
+    }
+    return true
+}
+
+// x: copy and clear the selection
+func cutSelection() {
+    if copySelection() {
+        clearSelection()
+    }
+}
+
+// n / Delete: empty the cells of the selection, the header stays when whole
+// columns are selected
+func clearSelection() {
+    if !rangeActive {
+        clearCell()
+        return
+    }
+    _, left, _, right := selectionBounds()
+    var ops batchOp
+    for _, r := range selectedRows() {
+        if headerReadOnly(r) || (r == 0 && rangeKind == selectColumns) {
+            continue
+        }
+        for c := left; c <= right; c++ {
+            if old := cellText(r, c); old != "" {
+                ops = append(ops, &setCellOp{row: r, col: c, oldText: old, newText: ""})
+            }
+        }
+    }
+    if len(ops) > 0 {
+        runOp(ops, selectedRow, selectedCol)
+    }
+}
+
+// v: paste the clipboard as a block at the cursor ( the top left of the
+// selection ), rows and columns are added when it does not fit
+func pasteClipboard() {
+    text, err := clipboardRead()
+    if err != nil {
+        showMessage("Clipboard read failed: %v", err)
+        return
+    }
+    top, left, _, _ := selectionBounds()
+    if len(data) == 0 || left >= numCols {
+        return
+    }
+    block := parseTSV(text)
+    if len(block) == 1 && len(block[0]) == 1 {
+        setCell(top, left, block[0][0])
+        clearRangeSelection()
+        return
+    }
+
+    width := 0
+    for _, cells := range block {
+        if len(cells) > width {
+            width = len(cells)
+        }
+    }
+    // The block goes to the rows that are shown, like copy takes them
+    var rows []int
+    for r := top; r < len(data) && len(rows) < len(block); r++ {
+        if rowVisible(r) {
+            rows = append(rows, r)
+        }
+    }
+    addRows := len(block) - len(rows)
+    for i := 0; i < addRows; i++ {
+        rows = append(rows, len(data)+i)
+    }
+    addCols := left + width - numCols
+    if addCols > 0 && loadBusy() {
+        showMessage("Still loading, columns can be added when the file is loaded")
+        return
+    }
+
+    var ops batchOp
+    for i := 0; i < addCols; i++ {
+        ops = append(ops, &insertColOp{at: numCols + i, cells: make([]string, len(data))})
+    }
+    for i := 0; i < addRows; i++ {
+        ops = append(ops, &insertRowOp{at: len(data) + i, cells: make([]string, numCols+max(addCols, 0))})
+    }
+    for i, cells := range block {
+        r := rows[i]
+        if headerReadOnly(r) {
+            continue
+        }
+        for j, text := range cells {
+            // Rows that are added are empty
+            old := ""
+            if r < len(data) {
+                old = cellText(r, left+j)
+            }
+            if old != text {
+                ops = append(ops, &setCellOp{row: r, col: left + j, oldText: old, newText: text})
+            }
+        }
+    }
+    clearRangeSelection()
+    if len(ops) == 0 {
+        return
+    }
+    if !runOp(ops, top, left) {
+        return
+    }
+
+    grown := ""
+    if addRows > 0 || addCols > 0 {
+        grown = fmt.Sprintf(" ( added %d row(s), %d column(s) )", max(addRows, 0), max(addCols, 0))
+    }
+    showMessage("Pasted %d x %d cells%s", len(block), width, grown)
+}
+
+// d with a selection: delete the selected rows ( not the header ), they are
+// copied to <file>.completed.csv first like a single deleted row
+func deleteSelectedRows() {
+    var rows []int
+    for _, r := range selectedRows() {
+        if r > 0 {
+            rows = append(rows, r)
+        }
+    }
+    if len(rows) == 0 || journalBusy() {
+        return
+    }
+    getUserConfirmation(fmt.Sprintf("Do you want to delete %d selected row(s)?", len(rows)), func() {
+        for _, r := range rows {
+            copyRowToCompleted(r)
+        }
+        var ops batchOp
+        // From the bottom, so the row numbers above stay valid
+        for i := len(rows) - 1; i >= 0; i-- {
+            ops = append(ops, &deleteRowOp{at: rows[i]})
+        }
+        clearRangeSelection()
+        after := rows[0]
+        if after > len(data)-1-len(rows) {
+            after = len(data) - 1 - len(rows)
+        }
+        runOp(ops, after, selectedCol)
+    })
+}
+
+// Backspace with a selection: delete the selected columns ( one stays )
+func deleteSelectedColumns() {
+    _, left, _, right := selectionBounds()
+    if right-left+1 >= numCols {
+        showMessage("The last column can not be deleted")
+        return
+    }
+    if loadBusy() {
+        return
+    }
+    getUserConfirmation(fmt.Sprintf("Do you want to delete %d selected column(s)?", right-left+1), func() {
+        var ops batchOp
+        for c := right; c >= left; c-- {
+            ops = append(ops, &deleteColOp{at: c})
+        }
+        clearRangeSelection()
+        after := left
+        if after > numCols-1-(right-left+1) {
+            after = numCols - 1 - (right - left + 1)
+        }
+        runOp(ops, selectedRow, after)
+    })
+}
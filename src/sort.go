@@ -0,0 +1,408 @@
+/**
+ * MIT License
+ *
+ * Copyright (c) 2025 Viki (VN - initials of my first and last name)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ *
+ * Contact: contact@viki.design
+ * Website: https://www.viki.design
+ *
+ */
+
+
+package main
+
+/*
+  Sort:
+
+    Reorders the data rows ( the header stays on top ) by one or more columns.
+    The comparison is chosen per column from its contents: if every non empty
+    cell is a number the column is sorted numerically, if every non empty cell
+    is a date it is sorted by date, otherwise by "natural" text order ( case
+    insensitive, digit runs compared by value so "item 9" < "item 10" ).
+    Empty cells always go last. The sort is stable, rows that compare equal
+    keep their order.
+
+    With a range selected ( Shift + arrows ) only the selected rows are sorted.
+    While a filter is active only the visible rows are sorted, the hidden ones
+    keep their places. The whole sort is one reorderRowsOp, so Ctrl+Z puts
+    every row back.
+
+    Dates are read year first ( ISO ) or day first: 03/04/2025 and 03.04.2025
+    are both the 3rd of April, there is no month first form.
+*/
+
+import (
+    "fmt"
+    "sort"
+    "strconv"
+    "strings"
+    "time"
+    "unicode"
+)
+
+const (
+    sortAuto = iota
+    sortNumber
+    sortDate
+    sortText
+)
+
+var sortKindNames = []string{"auto", "number", "date", "text"}
+
+// Date formats recognised by the date comparison, day before month
+var sortDateLayouts = []string{
+    "2006-01-02",
+    "2006-01-02 15:04",
+    "2006-01-02 15:04:05",
+    time.RFC3339,
+    "2006/01/02",
+    "02.01.2006",
+    "2.1.2006",
+    "02/01/2006",
+    "2/1/2006",
+    "02 Jan 2006",
+    "Jan 2, 2006",
+}
+
+type sortKey struct {
+    col  int
+    desc bool
+    kind int
+}
+
+// Values of one key column for the rows being sorted
+type sortColumn struct {
+    sortKey
+    empty []bool
+    nums  []float64
+    texts []string
+}
+
+func parseSortNumber(text string) (float64, bool) {
+    n, err := strconv.ParseFloat(strings.TrimSpace(text), 64)
+    return n, err == nil
+}
+
+func parseSortDate(text string) (time.Time, bool) {
+    text = strings.TrimSpace(text)
+    for _, layout := range sortDateLayouts {
+        if t, err := time.Parse(layout, text); err == nil {
+            return t, true
+        }
+    }
+    return time.Time{}, false
+}
+
+// Pick number / date / text for a column from the cells of the rows
+func detectSortKind(col int, rows []int) int {
+    numbers, dates, values := true, true, 0
+    for i := 0; i < len(rows) && (numbers || dates); i++ {
+        text := strings.TrimSpace(cellText(rows[i], col))
+        if text == "" {
+            continue
+        }
+        values += 1
+        if numbers {
+            _, numbers = parseSortNumber(text)
+        }
+        if dates {
+            _, dates = parseSortDate(text)
+        }
+    }
+    switch {
+    case values == 0:
+        return sortText
+    case numbers:
+        return sortNumber
+    case dates:
+        return sortDate
+    }
+    return sortText
+}
+
+// Natural order: case insensitive, runs of digits compared by their value
+func naturalCompare(a string, b string) int {
+    ra, rb := []rune(a), []rune(b)
+    i, j := 0, 0
+    for i < len(ra) && j < len(rb) {
+        if unicode.IsDigit(ra[i]) && unicode.IsDigit(rb[j]) {
+            si := i
+            for i < len(ra) && unicode.IsDigit(ra[i]) {
+                i++
+            }
+            sj := j
+            for j < len(rb) && unicode.IsDigit(rb[j]) {
+                j++
+            }
+            da := strings.TrimLeft(string(ra[si:i]), "0")
+            db := strings.TrimLeft(string(rb[sj:j]), "0")
+            if len(da) != len(db) {
+                return len(da) - len(db)
+            }
+            if c := strings.Compare(da, db); c != 0 {
+                return c
+            }
+            continue
+        }
+        ca, cb := unicode.ToLower(ra[i]), unicode.ToLower(rb[j])
+        if ca != cb {
+            if ca < cb {
+                return -1
+            }
+            return 1
+        }
+        i++
+        j++
+    }
+    return (len(ra) - i) - (len(rb) - j)
+}
+
+func newSortColumn(key sortKey, rows []int) *sortColumn {
+    if key.kind == sortAuto {
+        key.kind = detectSortKind(key.col, rows)
+    }
+
+    n := len(rows)
+    sc := &sortColumn{sortKey: key, empty: make([]bool, n)}
+    if key.kind == sortText {
+        sc.texts = make([]string, n)
+    } else {
+        sc.nums = make([]float64, n)
+    }
+
+    for i := 0; i < n; i++ {
+        text := strings.TrimSpace(cellText(rows[i], key.col))
+        switch key.kind {
+        case sortNumber:
+            num, ok := parseSortNumber(text)
+            sc.nums[i], sc.empty[i] = num, !ok
+        case sortDate:
+            t, ok := parseSortDate(text)
+            sc.nums[i], sc.empty[i] = float64(t.Unix()), !ok
+        default:
+            sc.texts[i], sc.empty[i] = text, text == ""
+        }
+    }
+    return sc
+}
+
+// Compare rows[i] and rows[j] on this column
+func (sc *sortColumn) compare(i int, j int) int {
+    // Empty ( or unparsable ) cells go last in both directions
+    if sc.empty[i] || sc.empty[j] {
+        switch {
+        case sc.empty[i] && sc.empty[j]:
+            return 0
+        case sc.empty[i]:
+            return 1
+        }
+        return -1
+    }
+
+    c := 0
+    if sc.texts != nil {
+        c = naturalCompare(sc.texts[i], sc.texts[j])
+    } else if sc.nums[i] < sc.nums[j] {
+        c = -1
+    } else if sc.nums[i] > sc.nums[j] {
+        c = 1
+    }
+    if sc.desc {
+        c = -c
+    }
+    return c
+}
+
+// New order of rows[0] .. rows[last] as a permutation of that range:
+// order[i] is the old ( relative ) index of the row that ends up at from+i.
+// Rows in the range that are not in rows ( hidden by the filter ) stay put.
+func sortOrder(keys []sortKey, rows []int) ([]int, []*sortColumn) {
+    columns := make([]*sortColumn, len(keys))
+    for i, key := range keys {
+        columns[i] = newSortColumn(key, rows)
+    }
+
+    sorted := make([]int, len(rows))
+    for i := range sorted {
+        sorted[i] = i
+    }
+    sort.SliceStable(sorted, func(a, b int) bool {
+        for _, sc := range columns {
+            if c := sc.compare(sorted[a], sorted[b]); c != 0 {
+                return c < 0
+            }
+        }
+        return false
+    })
+
+    from := rows[0]
+    order := make([]int, rows[len(rows)-1]-from+1)
+    for i := range order {
+        order[i] = i
+    }
+    for i, r := range rows {
+        order[r-from] = rows[sorted[i]] - from
+    }
+    return order, columns
+}
+
+// Rows to sort: the visible selected rows, or every visible data row
+func sortRange() []int {
+    from, to := 1, len(data)-1
+    if rangeActive {
+        top, _, bottom, _ := selectionBounds()
+        if bottom > top {
+            from, to = max(top, 1), bottom
+        }
+    }
+
+    var rows []int
+    for r := from; r <= to; r++ {
+        if rowVisible(r) {
+            rows = append(rows, r)
+        }
+    }
+    return rows
+}
+
+func sortRows(keys []sortKey) {
+    if loadBusy() {
+        return
+    }
+    rows := sortRange()
+    if len(rows) < 2 {
+        showMessage("Nothing to sort")
+        return
+    }
+
+    order, columns := sortOrder(keys, rows)
+    from, to := rows[0], rows[len(rows)-1]
+
+    var desc []string
+    for _, sc := range columns {
+        name := strings.TrimSpace(cellText(0, sc.col))
+        if name == "" {
+            name = fmt.Sprintf("col %d", sc.col+1)
+        }
+        d := fmt.Sprintf("%s (%s", name, sortKindNames[sc.kind])
+        if sc.desc {
+            d += ", descending"
+        }
+        desc = append(desc, d+")")
+    }
+    what := fmt.Sprintf("%d rows by %s", len(rows), strings.Join(desc, ", "))
+
+    changed := false
+    for i, o := range order {
+        if i != o {
+            changed = true
+            break
+        }
+    }
+    if !changed {
+        showMessage("Already sorted: %s", what)
+        return
+    }
+
+    // Keep the cursor on the row it was on
+    afterRow := selectedRow
+    if selectedRow >= from && selectedRow <= to {
+        for i, o := range order {
+            if from+o == selectedRow {
+                afterRow = from + i
+                break
+            }
+        }
+    }
+
+    if !runOp(&reorderRowsOp{from: from, order: order}, afterRow, selectedCol) {
+        return
+    }
+    showMessage("Sorted %s", what)
+}
+
+// Sort by the selected column ( 'o' / 'O' )
+func sortByCurrentColumn(desc bool) {
+    sortRows([]sortKey{{col: selectedCol, desc: desc}})
+}
+
+// Parse one :sort argument: [-]column[:number|date|text]
+func parseSortKey(arg string) (sortKey, error) {
+    key := sortKey{}
+    if strings.HasPrefix(arg, "-") {
+        key.desc = true
+        arg = arg[1:]
+    } else if strings.HasPrefix(arg, "+") {
+        arg = arg[1:]
+    }
+
+    // A suffix that is not a sort type is part of the column name
+    if i := strings.LastIndex(arg, ":"); i >= 0 && i < len(arg)-1 {
+        kind := strings.ToLower(arg[i+1:])
+        for k, name := range sortKindNames {
+            if strings.HasPrefix(name, kind) {
+                key.kind = k
+                arg = arg[:i]
+                break
+            }
+        }
+    }
+
+    col, err := columnIndex(arg)
+    if err != nil {
+        return key, err
+    }
+    key.col = col
+    return key, nil
+}
+
+func cmdSort(bang bool, args string) error {
+    var keys []sortKey
+    for _, arg := range splitArgs(args) {
+        key, err := parseSortKey(arg)
+        if err != nil {
+            return err
+        }
+        keys = append(keys, key)
+    }
+    if len(keys) == 0 {
+        keys = []sortKey{{col: selectedCol}}
+    }
+    // :sort! reverses every key
+    if bang {
+        for i := range keys {
+            keys[i].desc = !keys[i].desc
+        }
+    }
+    sortRows(keys)
+    return nil
+}
+
+func registerSortCommands() {
+    registerCommand(&command{
+        name: "sort",
+        args: "[-]<column>[:number|date|text] ...",
+        help: "Sort the rows ( or the selected rows ) by one or more columns, - for descending, ! reverses",
+        run:  cmdSort,
+    })
+}
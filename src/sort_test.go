@@ -0,0 +1,158 @@
+/**
+ * MIT License
+ *
+ * Copyright (c) 2025 Viki (VN - initials of my first and last name)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ *
+ * Contact: contact@viki.design
+ * Website: https://www.viki.design
+ *
+ */
+
+package main
+import (
+    "reflect"
+    "testing"
+    "time"
+)
+
+func TestNaturalCompare(t *testing.T) {
+    tests := []struct {
+        a, b string
+        want int // sign
+    }{
+        {"a", "b", -1},
+        {"B", "a", 1},
+        {"abc", "ABC", 0},
+        {"item2", "item10", -1},
+        {"item10", "item2", 1},
+        {"item02", "item2", 0},
+        {"x9y", "x10a", -1},
+        {"a", "ab", -1},
+        {"", "a", -1},
+        {"1.5", "1.10", -1},
+    }
+    for _, tt := range tests {
+        got := naturalCompare(tt.a, tt.b)
+        if got < 0 && tt.want >= 0 || got > 0 && tt.want <= 0 || got == 0 && tt.want != 0 {
+            t.Errorf("naturalCompare(%q, %q) = %d, want sign %d", tt.a, tt.b, got, tt.want)
+        }
+    }
+}
+
+// Numeric dates are day first
+func TestParseSortDate(t *testing.T) {
+    tests := []struct {
+        text string
+        want string // "" if not a date
+    }{
+        {"2025-10-01", "2025-10-01"},
+        {" 2025-10-01 12:30 ", "2025-10-01"},
+        {"2025/10/01", "2025-10-01"},
+        {"01.10.2025", "2025-10-01"},
+        {"1.10.2025", "2025-10-01"},
+        {"01/10/2025", "2025-10-01"},
+        {"1/10/2025", "2025-10-01"},
+        {"13/01/2025", "2025-01-13"},
+        {"01/13/2025", ""},
+        {"1 Oct 2025", ""},
+        {"01 Oct 2025", "2025-10-01"},
+        {"Oct 1, 2025", "2025-10-01"},
+        {"2025", ""},
+        {"soon", ""},
+    }
+    for _, tt := range tests {
+        d, ok := parseSortDate(tt.text)
+        got := ""
+        if ok {
+            got = d.Format(time.DateOnly)
+        }
+        if got != tt.want {
+            t.Errorf("parseSortDate(%q) = %q, want %q", tt.text, got, tt.want)
+        }
+    }
+}
+
+func TestSortOrder(t *testing.T) {
+    useTestData(t, [][]string{
+        {"name", "n", "date", "mixed"},
+        {"carl", "10", "02.01.2025", "b"},
+        {"ann", "9", "", "10"},
+        {"Bob", "", "01.02.2025", "a"},
+        {"dora", "9", "1/1/2025", "2"},
+    })
+    all := []int{1, 2, 3, 4}
+    tests := []struct {
+        keys []sortKey
+        rows []int
+        want []int // old relative index of the rows, in their new order
+        kind []int
+    }{
+        {[]sortKey{{col: 0}}, all, []int{1, 2, 0, 3}, []int{sortText}},
+        {[]sortKey{{col: 0, desc: true}}, all, []int{3, 0, 2, 1}, []int{sortText}},
+        // Numbers, empty cells last in both directions
+        {[]sortKey{{col: 1}}, all, []int{1, 3, 0, 2}, []int{sortNumber}},
+        {[]sortKey{{col: 1, desc: true}}, all, []int{0, 1, 3, 2}, []int{sortNumber}},
+        // Equal keys keep their order, the next key decides
+        {[]sortKey{{col: 1}, {col: 0, desc: true}}, all, []int{3, 1, 0, 2}, []int{sortNumber, sortText}},
+        {[]sortKey{{col: 2}}, all, []int{3, 0, 2, 1}, []int{sortDate}},
+        {[]sortKey{{col: 3}}, all, []int{3, 1, 2, 0}, []int{sortText}},
+        {[]sortKey{{col: 3, kind: sortNumber}}, all, []int{3, 1, 0, 2}, []int{sortNumber}},
+        // Rows that are not sorted ( hidden by a filter ) stay where they are
+        {[]sortKey{{col: 0}}, []int{1, 3, 4}, []int{2, 1, 0, 3}, []int{sortText}},
+    }
+    for _, tt := range tests {
+        order, columns := sortOrder(tt.keys, tt.rows)
+        var kinds []int
+        for _, sc := range columns {
+            kinds = append(kinds, sc.kind)
+        }
+        if !reflect.DeepEqual(order, tt.want) || !reflect.DeepEqual(kinds, tt.kind) {
+            t.Errorf("sortOrder(%v, %v) = %v kinds %v, want %v kinds %v", tt.keys, tt.rows, order, kinds, tt.want, tt.kind)
+        }
+    }
+}
+
+func TestParseSortKey(t *testing.T) {
+    useTestData(t, [][]string{{"name", "a:b", "due:date"}, {"x", "y", "z"}})
+    tests := []struct {
+        arg  string
+        want sortKey
+    }{
+        {"name", sortKey{col: 0}},
+        {"-NAME", sortKey{col: 0, desc: true}},
+        {"+2:n", sortKey{col: 1, kind: sortNumber}},
+        {"name:text", sortKey{col: 0, kind: sortText}},
+        // A suffix that is not a kind is part of the name
+        {"a:b", sortKey{col: 1}},
+        {"due:date:d", sortKey{col: 2, kind: sortDate}},
+    }
+    for _, tt := range tests {
+        got, err := parseSortKey(tt.arg)
+        if err != nil || got != tt.want {
+            t.Errorf("parseSortKey(%q) = %+v, %v, want %+v", tt.arg, got, err, tt.want)
+        }
+    }
+    if _, err := parseSortKey("size"); err == nil {
+        t.Errorf("parseSortKey(%q) gave no error", "size")
+    }
+}
@@ -0,0 +1,102 @@
+/**
+ * MIT License
+ *
+ * Copyright (c) 2025 Viki (VN - initials of my first and last name)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ *
+ * Contact: contact@viki.design
+ * Website: https://www.viki.design
+ *
+ */
+
+package main
+
+/*
+  Status bar:
+
+    One line below the table ( it replaces the white border line ) showing the
+    file name, a [+] when there are unsaved changes, the cursor position and the
+    last message. Messages go here instead of log.Printf, which corrupts the
+    table while the UI is running.
+*/
+
+import (
+    "fmt"
+
+    "github.com/gdamore/tcell/v2"
+    "github.com/rivo/tview"
+)
+
+var (
+    statusBar     *tview.TextView
+    statusMessage string
+)
+
+func statusBarInit() {
+    statusBar = tview.NewTextView()
+    statusBar.SetDynamicColors(false)
+    statusBar.SetTextColor(tcell.ColorBlack)
+    statusBar.SetBackgroundColor(tcell.ColorWhite)
+}
+
+func updateStatusBar() {
+    if statusBar == nil {
+        return
+    }
+    updateTabBar()
+
+    modifiedMark := ""
+    if doc.Dirty() {
+        modifiedMark = " [+]"
+    }
+
+    text := fmt.Sprintf(" %s%s   row %d/%d  col %d/%d", displayName(), modifiedMark, selectedRow, numRows-1, selectedCol+1, numCols)
+    if filterActive {
+        text += "   " + filterStatus()
+    }
+    if rangeActive {
+        text += "   " + selectionStatus()
+    }
+    if loading && loadTotalBytes == 0 {
+        // A pipe, the size is not known
+        text += fmt.Sprintf("   loading %.1f MB, %d rows", float64(loadReadBytes.Load())/1e6, numRows-1)
+    } else if loading {
+        text += fmt.Sprintf("   loading %.1f / %.1f MB, %d rows", float64(loadReadBytes.Load())/1e6, float64(loadTotalBytes)/1e6, numRows-1)
+    }
+    if statusMessage != "" {
+        text += "   | " + statusMessage
+    }
+    statusBar.SetText(text)
+}
+
+func showMessage(format string, args ...interface{}) {
+    statusMessage = fmt.Sprintf(format, args...)
+    updateStatusBar()
+}
+
+func clearMessage() {
+    if statusMessage == "" {
+        return
+    }
+    statusMessage = ""
+    updateStatusBar()
+}
@@ -0,0 +1,82 @@
+/**
+ * MIT License
+ *
+ * Copyright (c) 2025 Viki (VN - initials of my first and last name)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ *
+ * Contact: contact@viki.design
+ * Website: https://www.viki.design
+ *
+ */
+
+package main
+
+/*
+  Table content:
+
+    The table does not hold a copy of the csv. tview asks tableContent for the
+    cells of the rows / columns that are on screen only ( every draw ), so the
+    cost of a redraw depends on the terminal size and not on the file size.
+*/
+
+import (
+    "github.com/gdamore/tcell/v2"
+    "github.com/rivo/tview"
+)
+
+type tableContent struct {
+    // The table is never changed through tview, all changes go to `data`
+    tview.TableContentReadOnly
+}
+
+// row is a table row, which is a data row unless a filter is active
+func (tc *tableContent) GetCell(row, col int) *tview.TableCell {
+    row = dataRowOf(row)
+    if row < 0 || row >= len(data) || col < 0 || col >= numCols {
+        return nil
+    }
+    var cell *tview.TableCell
+    if row == 0 {
+        cell = tableHeaderCell(col)
+    } else {
+        cell = tableBodyCell(row, col)
+    }
+
+    if inRangeSelection(row, col) {
+        cell.SetTextColor(tcell.ColorWhite)
+        cell.SetBackgroundColor(tcell.ColorNavy)
+    } else if isSearchHit(row, col) {
+        cell.SetTextColor(tcell.ColorBlack)
+        cell.SetBackgroundColor(tcell.ColorYellow)
+    } else if rowMalformed(row) {
+        styleMalformedCell(cell, row, col)
+    }
+    return cell
+}
+
+func (tc *tableContent) GetRowCount() int {
+    return tableRowCount()
+}
+
+func (tc *tableContent) GetColumnCount() int {
+    return numCols
+}
@@ -0,0 +1,424 @@
+/**
+ * MIT License
+ *
+ * Copyright (c) 2025 Viki (VN - initials of my first and last name)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ *
+ * Contact: contact@viki.design
+ * Website: https://www.viki.design
+ *
+ */
+
+package main
+
+/*
+  Tabs:
+
+    csvgo a.csv b.csv opens every file in a tab of its own. The state of a
+    file lives in the package globals ( doc, undoStack, selectedRow ... ) like
+    before; a tab keeps a copy of them while another tab is shown, switching
+    stores the globals of the current tab and restores those of the next.
+    Every tab has its own table and flex ( a page of `pages` ), so the scroll
+    position stays where it was. The tab bar above the table shows up when
+    more than one file is open.
+
+    A file is loaded when its tab is shown the first time. Switching waits
+    until the current file is loaded ( the loader fills the globals ).
+
+    ] / [ and :tabnext / :tabprev cycle through the tabs, :tabe <file> opens
+    another one, q / :q close the current tab ( the last one quits ), :qa
+    quits all. y copies the current row ( or the rows of the selected range ),
+    p inserts them below the current row, also in another tab.
+*/
+
+import (
+    "fmt"
+    "os"
+    "path/filepath"
+    "strconv"
+    "strings"
+
+    "github.com/rivo/tview"
+
+    "neoviki_spreadsheet/modules_neoviki/csvio"
+    "neoviki_spreadsheet/modules_neoviki/document"
+)
+
+type tab struct {
+    id     string
+    file   string
+    loaded bool
+
+    table *tview.Table
+    flex  *tview.Flex
+
+    // Copies of the globals while the tab is not shown
+    doc                 *document.Document
+    colWidths           map[int]int
+    selectedRow         int
+    selectedCol         int
+    undoStack           []historyEntry
+    redoStack           []historyEntry
+    savedDepth          int
+    parseIssues         []parseIssue
+    quoteIssues         map[*csvio.RowFormat]bool
+    headerChoiceChanged bool
+    filterActive        bool
+    filterText          string
+    viewRows            []int
+    loadErr             error
+    keymap              *keyMap
+    journalStarted      bool
+    journalPending      bool
+}
+
+var (
+    // Files from the command line
+    inputFiles []string
+
+    tabs       []*tab
+    currentTab int
+    tabCount   int
+    tabBar     *tview.TextView
+
+    // Rows copied with y, inserted with p
+    yankedRows [][]string
+)
+
+func newTab(file string) *tab {
+    tabCount++
+    return &tab{id: strconv.Itoa(tabCount), file: file}
+}
+
+func tabBarInit() {
+    tabBar = tview.NewTextView()
+    tabBar.SetDynamicColors(true)
+}
+
+// The first tab is the file loaded by main, the others are loaded when shown
+func tabsInit() {
+    first := newTab(inputFile)
+    first.loaded = true
+    first.table, first.flex = table, flex
+    tabs = []*tab{first}
+    for _, file := range inputFiles[1:] {
+        tabs = append(tabs, newTab(file))
+    }
+    layoutTabBar()
+}
+
+func storeTab(t *tab) {
+    t.file = inputFile
+    t.table, t.flex = table, flex
+    t.doc = doc
+    t.colWidths = colWidths
+    t.selectedRow, t.selectedCol = selectedRow, selectedCol
+    t.undoStack, t.redoStack, t.savedDepth = undoStack, redoStack, savedDepth
+    t.parseIssues, t.quoteIssues, t.headerChoiceChanged = parseIssues, quoteIssues, headerChoiceChanged
+    t.filterActive, t.filterText, t.viewRows = filterActive, filterText, viewRows
+    t.loadErr = loadErr
+    t.keymap = keymap
+    t.journalStarted, t.journalPending = journalStarted, journalPending
+}
+
+func restoreTab(t *tab) {
+    inputFile = t.file
+    table, flex = t.table, t.flex
+    doc = t.doc
+    syncDocument()
+    colWidths = t.colWidths
+    selectedRow, selectedCol = t.selectedRow, t.selectedCol
+    undoStack, redoStack, savedDepth = t.undoStack, t.redoStack, t.savedDepth
+    parseIssues, quoteIssues, headerChoiceChanged = t.parseIssues, t.quoteIssues, t.headerChoiceChanged
+    filterActive, filterText, viewRows = t.filterActive, t.filterText, t.viewRows
+    loadErr = t.loadErr
+    keymap = t.keymap
+    journalStarted, journalPending = t.journalStarted, t.journalPending
+    pendingKeys = nil
+}
+
+// Table, flex and page of a tab that is shown the first time, then the file
+func openTab(t *tab) error {
+    tableInit()
+    setupKeybindings()
+    flexInit()
+    flexAddTable()
+    t.table, t.flex = table, flex
+
+    if err := loadCSV(t.file); err != nil {
+        return err
+    }
+    resetFileState()
+    pages.AddPage(t.id, flex, true, false)
+    t.loaded = true
+    startLoader()
+    return nil
+}
+
+// Show tab i, the current tab has been stored ( or closed ) already
+func activateTab(i int) error {
+    t := tabs[i]
+    if !t.loaded {
+        if err := openTab(t); err != nil {
+            return err
+        }
+    } else {
+        restoreTab(t)
+    }
+    currentTab = i
+    pages.SwitchToPage(t.id)
+    layoutTabBar()
+    refreshTable()
+    return nil
+}
+
+func switchTab(i int) {
+    if len(tabs) < 2 {
+        showMessage("Only one file is open ( :tabe <file> opens another one )")
+        return
+    }
+    i = (i + len(tabs)) % len(tabs)
+    if i == currentTab || loadBusy() {
+        return
+    }
+
+    old := currentTab
+    storeTab(tabs[old])
+    journalClose()
+    clearRangeSelection()
+    // Hits of a search belong to the file they were found in
+    clearSearch()
+    if err := activateTab(i); err != nil {
+        // The file could not be read, the tab goes away
+        tabs = append(tabs[:i], tabs[i+1:]...)
+        if i < old {
+            old--
+        }
+        restoreTab(tabs[old])
+        currentTab = old
+        layoutTabBar()
+        refreshTable()
+        showMessage("Error: %v", err)
+    }
+}
+
+// :tabe, a new tab right of the current one
+func openFileInTab(path string) error {
+    if stdoutFlag {
+        return fmt.Errorf("-stdout edits one file only")
+    }
+    if _, err := os.Stat(path); err != nil {
+        return err
+    }
+    if loadBusy() {
+        return nil
+    }
+    tabs = append(tabs, nil)
+    copy(tabs[currentTab+2:], tabs[currentTab+1:])
+    tabs[currentTab+1] = newTab(path)
+    switchTab(currentTab + 1)
+    return nil
+}
+
+// Close the current tab without asking, the last one quits
+func closeCurrentTab() {
+    if len(tabs) < 2 {
+        app.Stop()
+        return
+    }
+    // A loader still running would fill the globals of the next tab
+    loadGeneration.Add(1)
+    loading = false
+
+    journalClose()
+    clearRangeSelection()
+    clearSearch()
+    pages.RemovePage(tabs[currentTab].id)
+    tabs = append(tabs[:currentTab], tabs[currentTab+1:]...)
+
+    // The neighbour, or the next one when its file can not be read
+    next := min(currentTab, len(tabs)-1)
+    for {
+        err := activateTab(next)
+        if err == nil {
+            break
+        }
+        showMessage("Error: %v", err)
+        tabs = append(tabs[:next], tabs[next+1:]...)
+        if len(tabs) == 0 {
+            app.Stop()
+            return
+        }
+        next = min(next, len(tabs)-1)
+    }
+}
+
+// :qa, unsaved changes in any tab stop it unless bang
+func quitAll(bang bool) error {
+    storeTab(tabs[currentTab])
+    var dirty []string
+    for _, t := range tabs {
+        if t.doc != nil && t.doc.Dirty() {
+            dirty = append(dirty, tabName(t.file))
+        }
+    }
+    if len(dirty) > 0 && !bang {
+        return fmt.Errorf("unsaved changes in %s ( add ! to discard them )", strings.Join(dirty, ", "))
+    }
+    journalClose()
+    for _, t := range tabs {
+        if t.doc != nil && t.doc.Dirty() && t.file != stdinPath {
+            os.Remove(getJournalPath(t.file))
+        }
+    }
+    app.Stop()
+    return nil
+}
+
+func tabName(file string) string {
+    if file == stdinPath {
+        return "[stdin]"
+    }
+    return filepath.Base(file)
+}
+
+// The tab bar takes a line of the table once a second file is open
+func layoutTabBar() {
+    height := 0
+    if len(tabs) > 1 {
+        height = 1
+    }
+    for _, t := range tabs {
+        if t.flex != nil {
+            t.flex.ResizeItem(tabBar, height, 0)
+            t.flex.ResizeItem(t.table, tableHeight-height, 1)
+        }
+    }
+    updateTabBar()
+}
+
+func updateTabBar() {
+    if tabBar == nil || len(tabs) < 2 {
+        return
+    }
+    var b strings.Builder
+    for i, t := range tabs {
+        d := t.doc
+        if i == currentTab {
+            d = doc
+        }
+        mark := ""
+        if d != nil && d.Dirty() {
+            mark = " [+]"
+        }
+        label := fmt.Sprintf(" %d %s%s ", i+1, tview.Escape(tabName(t.file)), tview.Escape(mark))
+        if i == currentTab {
+            b.WriteString("[black:white]" + label + "[-:-]")
+        } else {
+            b.WriteString(label)
+        }
+        b.WriteString("│")
+    }
+    tabBar.SetText(b.String())
+}
+
+// y: copy the current row or the rows of the selected range
+func yankRows() {
+    top, _, bottom, _ := selectionBounds()
+    if top == 0 && !doc.HasHeader() {
+        // Column labels are not data ( see header.go )
+        top = 1
+    }
+    yankedRows = nil
+    for r := top; r <= bottom && r < len(data); r++ {
+        if rowVisible(r) {
+            yankedRows = append(yankedRows, append([]string(nil), data[r]...))
+        }
+    }
+    clearRangeSelection()
+    refreshTable()
+    showMessage("Copied %d row(s) ( p inserts them below the current row, in any tab )", len(yankedRows))
+}
+
+// p: insert the copied rows below the current row, short rows are padded to the header
+func putRows() {
+    if len(yankedRows) == 0 {
+        showMessage("No rows copied ( y copies the current row )")
+        return
+    }
+    at := selectedRow + 1
+    var ops batchOp
+    for i, row := range yankedRows {
+        cells := row
+        if len(cells) < len(data[0]) {
+            cells = make([]string, len(data[0]))
+            copy(cells, row)
+        }
+        ops = append(ops, &insertRowOp{at: at + i, cells: cells})
+    }
+    if !runOp(ops, at, selectedCol) {
+        return
+    }
+    showMessage("Inserted %d row(s) below row %d", len(yankedRows), at-1)
+}
+
+func registerTabCommands() {
+    registerCommand(&command{
+        name: "tabedit", aliases: []string{"tabe", "tabnew"}, args: "<file>", complete: "file",
+        help: "Open a csv file in a new tab",
+        run: func(bang bool, args string) error {
+            files := splitArgs(args)
+            if len(files) == 0 {
+                return fmt.Errorf("tabedit needs a file name")
+            }
+            return openFileInTab(files[0])
+        },
+    })
+    registerCommand(&command{
+        name: "tabnext", aliases: []string{"tabn"},
+        help: "Show the next tab ( ] )",
+        run: func(bang bool, args string) error {
+            switchTab(currentTab + 1)
+            return nil
+        },
+    })
+    registerCommand(&command{
+        name: "tabprevious", aliases: []string{"tabp", "tabprev"},
+        help: "Show the previous tab ( [ )",
+        run: func(bang bool, args string) error {
+            switchTab(currentTab - 1)
+            return nil
+        },
+    })
+    registerCommand(&command{
+        name: "tabclose", aliases: []string{"tabc"},
+        help: "Close the current tab ( tabc! discards unsaved changes )",
+        run: cmdQuit,
+    })
+    registerCommand(&command{
+        name: "qall", aliases: []string{"qa"},
+        help: "Quit, all tabs ( qa! discards unsaved changes )",
+        run: func(bang bool, args string) error {
+            return quitAll(bang)
+        },
+    })
+}
@@ -0,0 +1,1286 @@
+/**
+ * MIT License
+ *
+ * Copyright (c) 2025 Viki (VN - initials of my first and last name)
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ *
+ *
+ *
+ * Contact: contact@viki.design
+ * Website: https://www.viki.design
+ *
+ */
+
+package main
+
+/*
+  UI tests:
+
+    The whole application runs on a tcell.SimulationScreen: startUI writes
+    the csv files to a temp directory, boots the app like main does ( see
+    appInit ) and waits until the files are loaded. press() types keys, the
+    tests look at the files that were written and at the screen, a few
+    screens are compared with golden files in testdata/ui.
+
+        go test -run UI .             run the UI tests
+        go test -run UI . -update     write the golden files again
+
+    After the keys, press() waits until the app has handled them: a key the
+    app does not use ( syncKey ) is caught by the app's input capture, once
+    it arrives everything typed before it is done. Keys the app sends to
+    itself ( simulateRightArrowKeyPressEvent ) come later, so it waits until
+    the screen does not change anymore.
+*/
+
+import (
+    "flag"
+    "os"
+    "path/filepath"
+    "slices"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/gdamore/tcell/v2"
+)
+
+var updateGolden = flag.Bool("update", false, "write the golden files in testdata/ui again")
+
+const (
+    uiWidth  = 100
+    uiHeight = 40
+
+    syncKey = tcell.KeyF64
+)
+
+type uiHarness struct {
+    t      *testing.T
+    dir    string
+    screen tcell.SimulationScreen
+
+    // Closed when the app stopped ( q, :wq ... )
+    done   chan struct{}
+    synced chan struct{}
+
+    clipboard string
+}
+
+// A key with modifiers, see press
+type keyPress struct {
+    key tcell.Key
+    mod tcell.ModMask
+}
+
+func shift(key tcell.Key) keyPress {
+    return keyPress{key, tcell.ModShift}
+}
+
+// Everything the app keeps between two files, the tests start from scratch
+func resetAppState() {
+    commands, options = nil, nil
+    commandHistory, historyPos = nil, 0
+    selectedRow, selectedCol = 0, 0
+    editing = false
+    colWidths = nil
+    undoStack, redoStack, savedDepth = nil, nil, 0
+    undoDepth = defaultUndoDepth
+    filterActive, filterText, viewRows, filterPrevText = false, "", nil, ""
+    searchPattern, searchActive, searchMatcher = "", false, nil
+    searchIgnoreCase, searchWholeCell, searchRegex = true, false, false
+    replaceFind, replaceWith, replaceRegexMode, replaceIgnoreCase = "", "", false, false
+    replaceScope = replaceScopeColumn
+    rangeActive, visualMode, rangeKind = false, false, selectCells
+    statusMessage = ""
+    labelStyle = "letters"
+    noHeaderFlag, strictParse, stdoutFlag = false, false, false
+    delimiterFlag, quoteFlag, encodingFlag = "", "", ""
+    tabs, currentTab, tabCount, yankedRows = nil, 0, 0, nil
+    journalFile, journalWriter, journalStarted, journalPending = nil, nil, false, false
+    longCell = defaultLongCell
+    actions, keymap, pendingKeys, keymapPreset, keymapErrors = nil, nil, nil, "", nil
+}
+
+// files: name and contents, in the order they are opened ( tabs )
+func startUI(t *testing.T, files ...string) *uiHarness {
+    t.Helper()
+    h := &uiHarness{t: t, dir: t.TempDir(), done: make(chan struct{}), synced: make(chan struct{}, 1)}
+
+    // The app works with the names as given, relative to the directory of the files
+    cwd, err := os.Getwd()
+    if err != nil {
+        t.Fatal(err)
+    }
+    if err := os.Chdir(h.dir); err != nil {
+        t.Fatal(err)
+    }
+    t.Cleanup(func() { os.Chdir(cwd) })
+    // Keymap files of the user are not read, h.dir/csvgo/keymap is the global one
+    t.Setenv("XDG_CONFIG_HOME", h.dir)
+
+    var names []string
+    for i := 0; i+1 < len(files); i += 2 {
+        h.write(files[i], files[i+1])
+        names = append(names, files[i])
+    }
+
+    resetAppState()
+    inputFile, inputFiles = names[0], names
+    clipboardWrite = func(text string) error {
+        h.clipboard = text
+        return nil
+    }
+    clipboardRead = func() (string, error) {
+        return h.clipboard, nil
+    }
+    if err := appInit(); err != nil {
+        t.Fatalf("appInit: %v", err)
+    }
+
+    h.screen = tcell.NewSimulationScreen("UTF-8")
+    app.SetScreen(h.screen)
+    h.screen.SetSize(uiWidth, uiHeight)
+    app.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+        if event.Key() == syncKey {
+            h.synced <- struct{}{}
+            return nil
+        }
+        return event
+    })
+    app.SetRoot(pages, true)
+
+    go func() {
+        defer close(h.done)
+        if err := app.Run(); err != nil {
+            t.Errorf("app.Run: %v", err)
+        }
+    }()
+    t.Cleanup(h.stop)
+
+    h.waitLoaded()
+    return h
+}
+
+func (h *uiHarness) stop() {
+    if !h.stopped() {
+        app.Stop()
+        <-h.done
+    }
+    // A journal that is still open belongs to the temp directory
+    journalClose()
+}
+
+func (h *uiHarness) stopped() bool {
+    select {
+    case <-h.done:
+        return true
+    default:
+        return false
+    }
+}
+
+// Run f on the UI goroutine, false if the app stopped
+func (h *uiHarness) do(f func()) bool {
+    ran := make(chan struct{})
+    go app.QueueUpdate(func() {
+        f()
+        close(ran)
+    })
+    select {
+    case <-ran:
+        return true
+    case <-h.done:
+        return false
+    case <-time.After(5 * time.Second):
+        h.t.Fatal("the UI does not respond")
+        return false
+    }
+}
+
+func (h *uiHarness) waitLoaded() {
+    h.t.Helper()
+    for i := 0; i < 500; i++ {
+        var busy bool
+        if !h.do(func() { busy = loading }) || !busy {
+            h.sync()
+            return
+        }
+        time.Sleep(10 * time.Millisecond)
+    }
+    h.t.Fatal("the file does not finish loading")
+}
+
+// Wait until the app handled every key and the screen does not change
+func (h *uiHarness) sync() {
+    h.t.Helper()
+    prev := ""
+    for stable := 0; stable < 3; {
+        h.screen.InjectKey(syncKey, 0, tcell.ModNone)
+        select {
+        case <-h.synced:
+        case <-h.done:
+            return
+        case <-time.After(5 * time.Second):
+            h.t.Fatal("the UI does not respond")
+        }
+        // The screen is drawn after the key
+        if !h.do(func() {}) {
+            return
+        }
+        text := h.text()
+        if text == prev {
+            stable++
+        } else {
+            stable = 0
+        }
+        prev = text
+    }
+}
+
+// press types keys: a string is typed rune by rune, a tcell.Key or a
+// keyPress is pressed as it is
+func (h *uiHarness) press(keys ...any) {
+    h.t.Helper()
+    for _, k := range keys {
+        switch k := k.(type) {
+        case string:
+            for _, r := range k {
+                h.screen.InjectKey(tcell.KeyRune, r, tcell.ModNone)
+            }
+        case tcell.Key:
+            h.screen.InjectKey(k, 0, tcell.ModNone)
+        case keyPress:
+            h.screen.InjectKey(k.key, 0, k.mod)
+        default:
+            h.t.Fatalf("press: %T is not a key", k)
+        }
+        // One key at a time, some keys open dialogs that take the next ones
+        h.sync()
+    }
+}
+
+// Run a command line command ( without the : )
+func (h *uiHarness) command(line string) {
+    h.t.Helper()
+    h.press(":", line, tcell.KeyEnter)
+}
+
+// Change the selected cell with e
+func (h *uiHarness) edit(text string) {
+    h.t.Helper()
+    h.press("e", tcell.KeyCtrlU, text, tcell.KeyEnter)
+}
+
+// A copy of the screen, taken on the UI goroutine: the app draws while the
+// test reads
+func (h *uiHarness) contents() ([]tcell.SimCell, int, int) {
+    var cells []tcell.SimCell
+    var width, height int
+    read := func() {
+        var screen []tcell.SimCell
+        screen, width, height = h.screen.GetContents()
+        cells = make([]tcell.SimCell, len(screen))
+        for i, c := range screen {
+            cells[i] = c
+            cells[i].Runes = slices.Clone(c.Runes)
+        }
+    }
+    if !h.do(read) {
+        // Stopped, nothing draws anymore
+        read()
+    }
+    return cells, width, height
+}
+
+// Screen rows without trailing blanks
+func (h *uiHarness) text() string {
+    cells, width, height := h.contents()
+    lines := make([]string, height)
+    for y := 0; y < height; y++ {
+        var b strings.Builder
+        for x := 0; x < width; x++ {
+            runes := cells[y*width+x].Runes
+            if len(runes) == 0 {
+                b.WriteRune(' ')
+                continue
+            }
+            b.WriteString(string(runes))
+        }
+        lines[y] = strings.TrimRight(b.String(), " ")
+    }
+    return strings.TrimRight(strings.Join(lines, "\n"), "\n") + "\n"
+}
+
+func (h *uiHarness) line(y int) string {
+    return strings.Split(h.text(), "\n")[y]
+}
+
+func (h *uiHarness) style(x int, y int) tcell.Style {
+    cells, width, _ := h.contents()
+    return cells[y*width+x].Style
+}
+
+// The status bar is the line below the table
+func (h *uiHarness) status() string {
+    for _, line := range strings.Split(h.text(), "\n") {
+        if strings.Contains(line, "  row ") {
+            return strings.TrimSpace(line)
+        }
+    }
+    return ""
+}
+
+func (h *uiHarness) expectStatus(want string) {
+    h.t.Helper()
+    if got := h.status(); !strings.Contains(got, want) {
+        h.t.Errorf("status bar %q does not contain %q", got, want)
+    }
+}
+
+func (h *uiHarness) expectScreen(want string) {
+    h.t.Helper()
+    if !strings.Contains(h.text(), want) {
+        h.t.Errorf("screen does not show %q:\n%s", want, h.text())
+    }
+}
+
+func (h *uiHarness) expectCursor(row int, col int) {
+    h.t.Helper()
+    var r, c int
+    h.do(func() { r, c = selectedRow, selectedCol })
+    if r != row || c != col {
+        h.t.Errorf("cursor on row %d col %d, want row %d col %d", r, c, row, col)
+    }
+}
+
+// Select a button of the dialog that is open and press it
+func (h *uiHarness) choose(label string) {
+    h.t.Helper()
+    for i := 0; i < 6; i++ {
+        if h.buttonActive(label) {
+            h.press(tcell.KeyEnter)
+            return
+        }
+        h.press(tcell.KeyRight)
+    }
+    h.t.Fatalf("no button %q:\n%s", label, h.text())
+}
+
+// Buttons of the dialogs are yellow when they are selected
+func (h *uiHarness) buttonActive(label string) bool {
+    for y, line := range strings.Split(h.text(), "\n") {
+        x := strings.Index(line, " "+label+" ")
+        if x < 0 {
+            continue
+        }
+        x = len([]rune(line[:x])) + 1
+        fg, _, _ := h.style(x, y).Decompose()
+        return fg == tcell.ColorYellow
+    }
+    return false
+}
+
+func (h *uiHarness) expectSelection(top int, left int, bottom int, right int) {
+    h.t.Helper()
+    var t, l, b, r int
+    h.do(func() { t, l, b, r = selectionBounds() })
+    if t != top || l != left || b != bottom || r != right {
+        h.t.Errorf("selection %d,%d - %d,%d, want %d,%d - %d,%d", t, l, b, r, top, left, bottom, right)
+    }
+}
+
+func (h *uiHarness) write(name string, text string) {
+    if err := os.WriteFile(filepath.Join(h.dir, name), []byte(text), 0644); err != nil {
+        h.t.Fatal(err)
+    }
+}
+
+func (h *uiHarness) read(name string) string {
+    b, err := os.ReadFile(filepath.Join(h.dir, name))
+    if err != nil {
+        h.t.Fatal(err)
+    }
+    return string(b)
+}
+
+func (h *uiHarness) expectFile(name string, want string) {
+    h.t.Helper()
+    if got := h.read(name); got != want {
+        h.t.Errorf("%s is\n%q\nwant\n%q", name, got, want)
+    }
+}
+
+// Compare the screen with testdata/ui/<name>.golden ( -update writes it )
+func (h *uiHarness) golden(name string) {
+    h.t.Helper()
+    path := filepath.Join(goldenDir, name+".golden")
+    got := h.text()
+    if *updateGolden {
+        if err := os.MkdirAll(goldenDir, 0755); err != nil {
+            h.t.Fatal(err)
+        }
+        if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+            h.t.Fatal(err)
+        }
+        return
+    }
+    want, err := os.ReadFile(path)
+    if err != nil {
+        h.t.Fatalf("%v ( -update writes it )", err)
+    }
+    if got != string(want) {
+        h.t.Errorf("screen differs from %s:\n%s", path, got)
+    }
+}
+
+// Absolute, the tests run in the temp directory of the files
+var goldenDir = func() string {
+    dir, _ := filepath.Abs(filepath.Join("testdata", "ui"))
+    return dir
+}()
+
+const people = "name,age,city\nann,30,Rome\nbob,40,Oslo\ncarl,25,Lima\n"
+
+func TestUIRender(t *testing.T) {
+    h := startUI(t, "people.csv", people)
+    h.golden("render")
+
+    // The header is yellow, the selected cell is highlighted
+    if fg, _, _ := h.style(12, 1).Decompose(); fg != tcell.ColorYellow {
+        t.Errorf("header color %v", fg)
+    }
+    if h.style(1, 1) == h.style(12, 1) {
+        t.Error("the selected cell looks like the others")
+    }
+}
+
+func TestUIArrows(t *testing.T) {
+    h := startUI(t, "people.csv", people)
+    h.press(tcell.KeyDown, tcell.KeyDown, tcell.KeyRight)
+    h.expectCursor(2, 1)
+    h.expectStatus("row 2/3  col 2/3")
+
+    h.press(tcell.KeyUp, tcell.KeyLeft)
+    h.expectCursor(1, 0)
+
+    // The cursor stops at the edges
+    h.press(tcell.KeyLeft, tcell.KeyUp, tcell.KeyUp)
+    h.expectCursor(0, 0)
+    h.press(tcell.KeyDown, tcell.KeyDown, tcell.KeyDown, tcell.KeyDown, tcell.KeyRight, tcell.KeyRight, tcell.KeyRight)
+    h.expectCursor(3, 2)
+}
+
+func TestUIEdit(t *testing.T) {
+    h := startUI(t, "people.csv", people)
+    h.press(tcell.KeyDown, "e")
+    h.golden("edit")
+    h.press(tcell.KeyCtrlU, "anna", tcell.KeyEnter)
+    h.expectStatus("people.csv [+]")
+
+    // i edits as well, Esc leaves the cell alone
+    h.press(tcell.KeyRight, "i", tcell.KeyCtrlU, "99", tcell.KeyEscape)
+
+    h.press(tcell.KeyCtrlS)
+    h.expectStatus("Saved people.csv")
+    h.expectFile("people.csv", "name,age,city\nanna,30,Rome\nbob,40,Oslo\ncarl,25,Lima\n")
+}
+
+func TestUIInsertRowAndColumn(t *testing.T) {
+    h := startUI(t, "people.csv", people)
+
+    // Enter: empty row below, Tab: empty column right of the cursor
+    h.press(tcell.KeyDown, tcell.KeyEnter)
+    h.expectCursor(2, 0)
+    h.edit("dan")
+    h.press(tcell.KeyTab)
+    h.expectCursor(2, 1)
+    h.edit("x")
+    h.golden("insert")
+
+    h.press(tcell.KeyCtrlS)
+    h.expectFile("people.csv", "name,,age,city\nann,,30,Rome\ndan,x,,\nbob,,40,Oslo\ncarl,,25,Lima\n")
+}
+
+func TestUIDeleteRow(t *testing.T) {
+    h := startUI(t, "people.csv", people)
+    h.press(tcell.KeyDown, tcell.KeyDown, "d")
+    h.golden("delete-row")
+
+    // No is selected first
+    if !h.buttonActive("No") {
+        t.Error("No is not preselected")
+    }
+    h.press(tcell.KeyEnter)
+    h.expectScreen("bob")
+
+    h.press("d")
+    h.choose("Yes")
+    h.press(tcell.KeyCtrlS)
+    h.expectFile("people.csv", "name,age,city\nann,30,Rome\ncarl,25,Lima\n")
+    // The deleted row is kept
+    h.expectFile("people.csv.completed.csv", "name,age,city\nbob,40,Oslo\n")
+
+    // The header stays
+    h.press(tcell.KeyUp, tcell.KeyUp, "d")
+    h.choose("Yes")
+    h.expectScreen("name")
+}
+
+func TestUIDeleteColumn(t *testing.T) {
+    h := startUI(t, "people.csv", people)
+    h.press(tcell.KeyRight, tcell.KeyBackspace2)
+    h.expectScreen("Do you want to delete selected")
+    h.choose("Yes")
+    h.press(tcell.KeyCtrlS)
+    h.expectFile("people.csv", "name,city\nann,Rome\nbob,Oslo\ncarl,Lima\n")
+
+    h.press(tcell.KeyBackspace)
+    h.choose("No")
+    h.press(tcell.KeyCtrlS)
+    h.expectFile("people.csv", "name,city\nann,Rome\nbob,Oslo\ncarl,Lima\n")
+}
+
+func TestUIUndoRedo(t *testing.T) {
+    h := startUI(t, "people.csv", people)
+    h.press(tcell.KeyDown)
+    h.edit("anna")
+    h.press(tcell.KeyTab)
+    h.expectCursor(1, 1)
+
+    h.press(tcell.KeyCtrlZ)
+    h.expectCursor(1, 0)
+    h.press(tcell.KeyCtrlZ)
+    h.press(tcell.KeyCtrlS)
+    h.expectFile("people.csv", people)
+
+    h.press(tcell.KeyCtrlY, tcell.KeyCtrlS)
+    h.expectFile("people.csv", "name,age,city\nanna,30,Rome\nbob,40,Oslo\ncarl,25,Lima\n")
+    h.press(tcell.KeyCtrlY, tcell.KeyCtrlS)
+    h.expectFile("people.csv", "name,,age,city\nanna,,30,Rome\nbob,,40,Oslo\ncarl,,25,Lima\n")
+}
+
+func TestUIUndoToSaved(t *testing.T) {
+    h := startUI(t, "people.csv", people)
+    h.press(tcell.KeyDown)
+    h.edit("anna")
+    h.press(tcell.KeyCtrlS)
+    h.edit("bea")
+    h.expectStatus("people.csv [+]")
+
+    // Undo / redo back to the saved state is no unsaved change
+    h.press(tcell.KeyCtrlZ)
+    if strings.Contains(h.status(), "[+]") {
+        t.Errorf("undo to the saved state, still changed: %q", h.status())
+    }
+    h.press(tcell.KeyCtrlZ)
+    h.expectStatus("people.csv [+]")
+    h.press(tcell.KeyCtrlY)
+    if strings.Contains(h.status(), "[+]") {
+        t.Errorf("redo to the saved state, still changed: %q", h.status())
+    }
+
+    // Nothing to save, q quits without asking
+    h.press("q")
+    if !h.stopped() {
+        t.Fatal("q after undo to the saved state did not quit")
+    }
+    if _, err := os.Stat(filepath.Join(h.dir, "people.csv.journal")); !os.IsNotExist(err) {
+        t.Error("the journal was not removed")
+    }
+    h.expectFile("people.csv", "name,age,city\nanna,30,Rome\nbob,40,Oslo\ncarl,25,Lima\n")
+}
+
+// A smaller undo depth drops the oldest ops, the saved state moves with them
+func TestUIUndoDepthSaved(t *testing.T) {
+    h := startUI(t, "people.csv", people)
+    h.press(tcell.KeyDown)
+    for _, name := range []string{"a", "b", "c", "d"} {
+        h.edit(name)
+    }
+    h.press(tcell.KeyCtrlS)
+    h.edit("e")
+    h.edit("f")
+    h.press(tcell.KeyCtrlZ, tcell.KeyCtrlZ)
+    h.command("set undodepth=2")
+    h.press(tcell.KeyCtrlY, tcell.KeyCtrlY)
+    h.expectStatus("people.csv [+]")
+
+    // The two edits are not lost on q
+    h.press("q")
+    h.expectScreen("Save & Quit")
+    h.choose("Save & Quit")
+    if !h.stopped() {
+        t.Fatal("Save & Quit did not quit")
+    }
+    h.expectFile("people.csv", "name,age,city\nf,30,Rome\nbob,40,Oslo\ncarl,25,Lima\n")
+}
+
+func TestUIClipboard(t *testing.T) {
+    h := startUI(t, "people.csv", people)
+
+    // c copies, v pastes, x cuts
+    h.press(tcell.KeyDown, tcell.KeyRight, tcell.KeyRight, "c")
+    if h.clipboard != "Rome" {
+        t.Errorf("clipboard %q", h.clipboard)
+    }
+    h.press(tcell.KeyDown, "v")
+    h.press(tcell.KeyLeft, "x")
+    if h.clipboard != "40" {
+        t.Errorf("clipboard %q", h.clipboard)
+    }
+
+    // n clears a cell
+    h.press(tcell.KeyDown, "n")
+    h.press(tcell.KeyCtrlS)
+    h.expectFile("people.csv", "name,age,city\nann,30,Rome\nbob,,Rome\ncarl,,Lima\n")
+}
+
+func TestUISaveAs(t *testing.T) {
+    h := startUI(t, "people.csv", people)
+    h.press(tcell.KeyDown)
+    h.edit("anna")
+    h.press("S")
+    h.expectScreen("Save as: ")
+    h.press(tcell.KeyCtrlU, "copy.csv", tcell.KeyEnter)
+    h.expectStatus("copy.csv")
+
+    h.expectFile("copy.csv", "name,age,city\nanna,30,Rome\nbob,40,Oslo\ncarl,25,Lima\n")
+    h.expectFile("people.csv", people)
+}
+
+func TestUICommandLine(t *testing.T) {
+    h := startUI(t, "people.csv", people)
+    h.press(":")
+    h.golden("command-line")
+    h.press("goto 3 city", tcell.KeyEnter)
+    h.expectCursor(3, 2)
+
+    h.command("set undodepth=5")
+    h.press(":", "set undodepth", tcell.KeyEnter)
+    h.expectStatus("undodepth=5")
+
+    h.command("unknown")
+    h.expectStatus("Error")
+
+    h.edit("Quito")
+    h.command("wq")
+    if !h.stopped() {
+        t.Fatal(":wq did not quit")
+    }
+    h.expectFile("people.csv", "name,age,city\nann,30,Rome\nbob,40,Oslo\ncarl,25,Quito\n")
+}
+
+func TestUIQuit(t *testing.T) {
+    h := startUI(t, "people.csv", people)
+    h.press("q")
+    if !h.stopped() {
+        t.Fatal("q without changes did not quit")
+    }
+}
+
+func TestUIQuitUnsaved(t *testing.T) {
+    h := startUI(t, "people.csv", people)
+    h.press(tcell.KeyDown)
+    h.edit("anna")
+    h.press("q")
+    h.golden("quit-unsaved")
+
+    // Cancel is preselected
+    h.press(tcell.KeyEnter)
+    if h.stopped() {
+        t.Fatal("Cancel quit")
+    }
+
+    h.press("q")
+    h.choose("Save & Quit")
+    if !h.stopped() {
+        t.Fatal("Save & Quit did not quit")
+    }
+    h.expectFile("people.csv", "name,age,city\nanna,30,Rome\nbob,40,Oslo\ncarl,25,Lima\n")
+}
+
+func TestUIQuitDiscard(t *testing.T) {
+    h := startUI(t, "people.csv", people)
+    h.press(tcell.KeyDown)
+    h.edit("anna")
+    h.press(tcell.KeyEscape)
+    h.choose("Discard & Quit")
+    if !h.stopped() {
+        t.Fatal("Discard & Quit did not quit")
+    }
+    h.expectFile("people.csv", people)
+    if _, err := os.Stat(filepath.Join(h.dir, "people.csv.journal")); !os.IsNotExist(err) {
+        t.Error("the journal was not removed")
+    }
+}
+
+func TestUIJournalSessions(t *testing.T) {
+    h := startUI(t, "people.csv", people)
+
+    // An undone change leaves something to redo
+    h.press(tcell.KeyDown)
+    h.edit("anna")
+    h.press(tcell.KeyCtrlZ)
+
+    // Two sessions in the journal, only the changes of the last one are recovered
+    h.do(func() {
+        journalClose()
+        h.write("people.csv.journal", "csvgo-journal,1\nset,1,0,ann,old\ncsvgo-journal,1\nset,1,1,30,31\n")
+        checkJournal()
+    })
+    h.sync()
+    h.expectScreen("Found 1 unsaved change(s)")
+    // Recover is preselected
+    h.press(tcell.KeyEnter)
+
+    // The recovered change can be undone, there is nothing to redo anymore
+    h.press(tcell.KeyCtrlY, tcell.KeyCtrlS)
+    h.expectFile("people.csv", "name,age,city\nann,31,Rome\nbob,40,Oslo\ncarl,25,Lima\n")
+    h.press(tcell.KeyCtrlZ, tcell.KeyCtrlS)
+    h.expectFile("people.csv", people)
+}
+
+func TestUIJournalWhileLoading(t *testing.T) {
+    h := startUI(t, "people.csv", people)
+
+    // The loader starts with the journal of a crashed session next to the file
+    stale := "csvgo-journal,1\nset,1,0,ann,anna\n"
+    h.write("people.csv.journal", stale)
+    h.do(func() {
+        loading = true
+        journalSessionStart()
+    })
+
+    // Changes wait for the question, the journal stays as it was
+    h.press(tcell.KeyDown)
+    h.edit("bea")
+    h.expectStatus("| Not changed")
+    h.press("p", "d", tcell.KeyEnter)
+    h.expectFile("people.csv.journal", stale)
+
+    // Loaded: only the change of the previous session is offered
+    h.do(func() { loadDone(nil) })
+    h.sync()
+    h.expectScreen("Found 1 unsaved change(s)")
+    // Recover is preselected
+    h.press(tcell.KeyEnter)
+    h.edit("bea")
+    h.press(tcell.KeyCtrlS)
+    h.expectFile("people.csv", "name,age,city\nbea,30,Rome\nbob,40,Oslo\ncarl,25,Lima\n")
+    h.press(tcell.KeyCtrlZ, tcell.KeyCtrlS)
+    h.expectFile("people.csv", "name,age,city\nanna,30,Rome\nbob,40,Oslo\ncarl,25,Lima\n")
+}
+
+// :e removes the journal of this session, not the one of a crashed session
+func TestUIEditKeepsJournal(t *testing.T) {
+    h := startUI(t, "people.csv", people)
+    h.write("towns.csv", "town;zip\nRome;001\n")
+    h.press(tcell.KeyDown)
+    h.edit("anna")
+    h.command("e! towns.csv")
+    h.expectStatus("towns.csv")
+    if _, err := os.Stat(filepath.Join(h.dir, "people.csv.journal")); !os.IsNotExist(err) {
+        t.Error("the journal of the abandoned edits was not removed")
+    }
+
+    stale := "csvgo-journal,1\nset,1,0,ann,anna\n"
+    h.command("e people.csv")
+    h.write("people.csv.journal", stale)
+    h.do(func() {
+        loading = true
+        journalSessionStart()
+    })
+    h.command("e towns.csv")
+    h.expectStatus("towns.csv")
+    h.expectFile("people.csv.journal", stale)
+}
+
+func TestUIRangeSelection(t *testing.T) {
+    h := startUI(t, "people.csv", people)
+
+    // Shift+arrows select, Esc clears the selection before it quits
+    h.press(tcell.KeyDown, shift(tcell.KeyDown), shift(tcell.KeyRight))
+    h.expectSelection(1, 0, 2, 1)
+    h.golden("range")
+    h.press(tcell.KeyEscape)
+    if h.stopped() {
+        t.Fatal("Esc quit instead of clearing the selection")
+    }
+    h.expectSelection(2, 1, 2, 1)
+
+    // y copies the rows of the selection
+    h.press(shift(tcell.KeyUp), "y")
+    h.expectStatus("Copied 2 row(s)")
+}
+
+func TestUIBlockClipboard(t *testing.T) {
+    h := startUI(t, "people.csv", people)
+
+    // c copies the selection as tab separated text
+    h.press(tcell.KeyDown, shift(tcell.KeyDown), shift(tcell.KeyRight), "c")
+    h.expectStatus("Copied 2 x 2 cells")
+    if h.clipboard != "ann\t30\nbob\t40" {
+        t.Errorf("clipboard %q", h.clipboard)
+    }
+
+    // v pastes it as a block, rows and columns are added to fit
+    h.press(tcell.KeyEscape, tcell.KeyRight, tcell.KeyDown, "v")
+    h.expectStatus("Pasted 2 x 2 cells ( added 1 row(s), 1 column(s) )")
+    h.press(tcell.KeyCtrlS)
+    h.expectFile("people.csv", "name,age,city,\nann,30,Rome,\nbob,40,Oslo,\ncarl,25,ann,30\n,,bob,40\n")
+
+    // One undo step
+    h.press(tcell.KeyCtrlZ, tcell.KeyCtrlS)
+    h.expectFile("people.csv", people)
+
+    // Quoted cells keep their tabs and line breaks, a single cell is pasted as it is
+    h.do(func() { h.clipboard = "\"a\tb\"\t\"line 1\nline 2\"\r\n" })
+    h.press("v", tcell.KeyCtrlS)
+    h.expectFile("people.csv", "name,age,city,\nann,30,Rome,\nbob,40,Oslo,\ncarl,25,a\tb,\"line 1\nline 2\"\n")
+    h.press(tcell.KeyRight, "c")
+    if h.clipboard != "\"line 1\nline 2\"" {
+        t.Errorf("clipboard %q", h.clipboard)
+    }
+    h.do(func() { h.clipboard = `say "hi"` })
+    h.press(tcell.KeyLeft, tcell.KeyUp, "v", tcell.KeyCtrlS)
+    h.expectFile("people.csv", "name,age,city,\nann,30,Rome,\nbob,40,\"say \"\"hi\"\"\",\ncarl,25,a\tb,\"line 1\nline 2\"\n")
+}
+
+// A paste that adds columns is recovered from the journal with what came after it
+func TestUIPasteJournal(t *testing.T) {
+    h := startUI(t, "people.csv", people)
+    h.do(func() { h.clipboard = "x\ty\tz" })
+    h.press(tcell.KeyDown, tcell.KeyRight, "v")
+    h.expectStatus("added 0 row(s), 1 column(s)")
+    h.press(tcell.KeyDown)
+    h.edit("41")
+    want := "name,age,city,\nann,x,y,z\nbob,41,Oslo,\ncarl,25,Lima,\n"
+
+    // The same changes on a copy of the file, as if the app had crashed
+    journal, err := os.ReadFile(filepath.Join(h.dir, "people.csv.journal"))
+    if err != nil {
+        t.Fatal(err)
+    }
+    h.write("crashed.csv", people)
+    h.write("crashed.csv.journal", string(journal))
+    h.command("e! crashed.csv")
+    h.expectScreen("Found 5 unsaved change(s)")
+    // Recover is preselected
+    h.press(tcell.KeyEnter, tcell.KeyCtrlS)
+    h.expectFile("crashed.csv", want)
+}
+
+// With a filter the block goes to the rows that are shown
+func TestUIPasteFiltered(t *testing.T) {
+    h := startUI(t, "people.csv", people)
+    h.press("f", "age != 40", tcell.KeyEnter)
+    h.do(func() { h.clipboard = "x\ny\nz" })
+    h.press(tcell.KeyDown, "v")
+    h.expectStatus("Pasted 3 x 1 cells ( added 1 row(s)")
+    h.press(tcell.KeyCtrlS)
+    h.expectFile("people.csv", "name,age,city\nx,30,Rome\nbob,40,Oslo\ny,25,Lima\nz,,\n")
+}
+
+func TestUIVisualSelection(t *testing.T) {
+    h := startUI(t, "people.csv", people)
+
+    // V selects whole rows, the arrow keys grow the selection
+    h.press(tcell.KeyDown, "V", tcell.KeyDown)
+    h.expectSelection(1, 0, 2, 2)
+    h.expectStatus("2 row(s) selected")
+    h.press("x")
+    if h.clipboard != "ann\t30\tRome\nbob\t40\tOslo" {
+        t.Errorf("clipboard %q", h.clipboard)
+    }
+    h.press(tcell.KeyCtrlZ)
+
+    // d deletes the selected rows, they go to the completed file
+    h.press("d")
+    h.expectScreen("Do you want to delete 2 selected")
+    h.choose("Yes")
+    h.press(tcell.KeyCtrlS)
+    h.expectFile("people.csv", "name,age,city\ncarl,25,Lima\n")
+    h.expectFile("people.csv.completed.csv", "name,age,city\nann,30,Rome\nbob,40,Oslo\n")
+    h.expectCursor(1, 0)
+
+    // | selects whole columns, n clears them but the header stays
+    h.press("|", tcell.KeyRight)
+    h.expectStatus("2 column(s) selected")
+    h.press("n", tcell.KeyCtrlS)
+    h.expectFile("people.csv", "name,age,city\n,,Lima\n")
+    h.press(tcell.KeyBackspace2)
+    h.choose("Yes")
+    h.press(tcell.KeyCtrlS)
+    h.expectFile("people.csv", "city\nLima\n")
+
+    // V again ends the selection
+    h.press("V")
+    h.expectStatus("1 row(s) selected")
+    h.press("V", tcell.KeyUp)
+    h.expectSelection(0, 0, 0, 0)
+}
+
+func TestUISearch(t *testing.T) {
+    h := startUI(t, "people.csv", people)
+    h.press("/", "o")
+    h.press(tcell.KeyEnter)
+    h.expectCursor(1, 2)
+
+    // n / N walk through the hits
+    h.press("n")
+    h.expectCursor(2, 0)
+    h.press("N")
+    h.expectCursor(1, 2)
+
+    h.press(tcell.KeyCtrlF)
+    h.golden("search-hits")
+    h.press(tcell.KeyEscape)
+
+    // Esc clears the search first, n clears cells again
+    h.press(tcell.KeyEscape)
+    if h.stopped() {
+        t.Fatal("Esc quit instead of clearing the search")
+    }
+    h.press("n", tcell.KeyCtrlS)
+    h.expectFile("people.csv", "name,age,city\nann,30,\nbob,40,Oslo\ncarl,25,Lima\n")
+}
+
+func TestUISort(t *testing.T) {
+    h := startUI(t, "people.csv", people)
+    h.press(tcell.KeyRight, "o", tcell.KeyCtrlS)
+    h.expectFile("people.csv", "name,age,city\ncarl,25,Lima\nann,30,Rome\nbob,40,Oslo\n")
+    h.press("O", tcell.KeyCtrlS)
+    h.expectFile("people.csv", "name,age,city\nbob,40,Oslo\nann,30,Rome\ncarl,25,Lima\n")
+
+    // With a filter only the rows shown are sorted, the hidden one keeps its place
+    h.press("f", "age != 30", tcell.KeyEnter, "o")
+    h.expectStatus("Sorted 2 rows")
+    h.command("nofilter")
+    h.press(tcell.KeyCtrlS)
+    h.expectFile("people.csv", "name,age,city\ncarl,25,Lima\nann,30,Rome\nbob,40,Oslo\n")
+}
+
+func TestUISortDates(t *testing.T) {
+    // Day first: 03/04 is the 3rd of April
+    h := startUI(t, "dates.csv", "due\n03/04/2025\n15/03/2025\n2.4.2025\n")
+    h.press("o", tcell.KeyCtrlS)
+    h.expectFile("dates.csv", "due\n15/03/2025\n2.4.2025\n03/04/2025\n")
+}
+
+func TestUIReplace(t *testing.T) {
+    h := startUI(t, "people.csv", people)
+    h.press(tcell.KeyRight, tcell.KeyRight, "R")
+    h.press("o", tcell.KeyTab, "0")
+    h.golden("replace")
+
+    // Replace all is the first button after the fields
+    h.press(tcell.KeyTab, tcell.KeyTab, tcell.KeyTab, tcell.KeyTab, tcell.KeyTab, tcell.KeyEnter)
+    h.expectStatus("Replaced 2 match(es) in 2 cell(s)")
+    h.press(tcell.KeyCtrlS)
+    h.expectFile("people.csv", "name,age,city\nann,30,R0me\nbob,40,Osl0\ncarl,25,Lima\n")
+}
+
+// "^$" matches empty cells only, a refused change reports nothing replaced
+func TestUIReplaceEmpty(t *testing.T) {
+    h := startUI(t, "people.csv", "name,age,city\nann,,Rome\nbob,40,\ncarl,,Lima\n")
+    h.press(tcell.KeyRight, "R")
+    h.press(tcell.KeyCtrlU, "^$", tcell.KeyTab, tcell.KeyCtrlU, "n/a", tcell.KeyTab, " ")
+    h.expectScreen("2 match(es) in 2 cell(s)")
+    h.press(tcell.KeyTab, tcell.KeyTab, tcell.KeyTab, tcell.KeyTab, tcell.KeyEnter)
+    h.expectStatus("Replaced 2 match(es) in 2 cell(s)")
+    h.press(tcell.KeyCtrlS)
+    h.expectFile("people.csv", "name,age,city\nann,n/a,Rome\nbob,40,\ncarl,n/a,Lima\n")
+
+    h.press(tcell.KeyRight, "R")
+    h.do(func() { journalPending = true })
+    h.press(tcell.KeyTab, tcell.KeyTab, tcell.KeyTab, tcell.KeyTab, tcell.KeyTab, tcell.KeyTab, tcell.KeyEnter)
+    h.expectStatus("| Not changed")
+    h.do(func() { journalPending = false })
+    h.press(tcell.KeyCtrlS)
+    h.expectFile("people.csv", "name,age,city\nann,n/a,Rome\nbob,40,\ncarl,n/a,Lima\n")
+}
+
+func TestUIFilter(t *testing.T) {
+    h := startUI(t, "people.csv", people)
+    h.press("f", "age >= 30", tcell.KeyEnter)
+    h.golden("filter")
+    h.expectStatus("2 of 3")
+
+    // Edits go to the rows that are shown
+    h.press(tcell.KeyDown, tcell.KeyDown)
+    h.edit("bobby")
+    h.command("nofilter")
+    h.press(tcell.KeyCtrlS)
+    h.expectFile("people.csv", "name,age,city\nann,30,Rome\nbobby,40,Oslo\ncarl,25,Lima\n")
+}
+
+func TestUIMalformed(t *testing.T) {
+    h := startUI(t, "bad.csv", "a,b\n1,2\n3\n4,5\n6,7,8\n")
+    h.golden("malformed")
+
+    // m / M jump to the next / previous malformed row
+    h.press("m")
+    h.expectCursor(2, 0)
+    h.press("m")
+    h.expectCursor(4, 0)
+    h.press("M")
+    h.expectCursor(2, 0)
+
+    // Malformed rows are red
+    if fg, _, _ := h.style(12, 5).Decompose(); fg != tcell.ColorRed {
+        t.Errorf("malformed row color %v", fg)
+    }
+}
+
+func TestUIHeaderless(t *testing.T) {
+    h := startUI(t, "nums.csv", "1,2,3\n4,5,6\n")
+    h.golden("headerless")
+
+    // The labels are not part of the file
+    h.press(tcell.KeyDown)
+    h.edit("x")
+    h.press(tcell.KeyCtrlS)
+    h.expectFile("nums.csv", "x,2,3\n4,5,6\n")
+
+    // :pad! leaves the labels alone
+    h.write("nums.csv", "1,2\n3,4,5\n6\n")
+    h.command("e nums.csv")
+    h.command("pad!")
+    h.expectStatus("Padded 2 row(s) to 3 fields")
+    h.expectFile("nums.csv.journal", "csvgo-journal,1\nsetrow,1,1,2,\nsetrow,3,6,,\n")
+    h.press(tcell.KeyCtrlS)
+    h.expectFile("nums.csv", "1,2,\n3,4,5\n6,,\n")
+}
+
+// The dialect flags apply to every file on the command line, not to :e
+func TestUITabsFlags(t *testing.T) {
+    h := startUI(t, "people.csv", people, "towns.csv", "town,zip\nRome,001\n")
+    h.do(func() { noHeaderFlag = true })
+    h.press("]")
+    h.expectStatus("towns.csv")
+    h.command("dialect")
+    h.expectStatus("header=no")
+
+    h.write("other.csv", "town,zip\nRome,001\n")
+    h.command("e other.csv")
+    h.command("dialect")
+    h.expectStatus("header=yes")
+}
+
+func TestUITabs(t *testing.T) {
+    h := startUI(t, "people.csv", people, "towns.csv", "town;zip\nRome;001\n")
+    h.golden("tabs")
+
+    // ] / [ switch the tabs, y / p copy rows between them
+    h.press("]")
+    h.expectStatus("towns.csv")
+    h.press(tcell.KeyDown, "y", "[")
+    h.expectStatus("people.csv")
+    h.press("p")
+    h.expectStatus("people.csv [+]")
+    h.expectScreen(" 1 people.csv [+] │ 2 towns.csv │")
+
+    h.press(tcell.KeyCtrlS)
+    // Padded to the header
+    h.expectFile("people.csv", "name,age,city\nRome,001,\nann,30,Rome\nbob,40,Oslo\ncarl,25,Lima\n")
+    h.expectFile("towns.csv", "town;zip\nRome;001\n")
+
+    // q closes a tab, the last one quits
+    h.press("q")
+    h.expectStatus("towns.csv")
+    h.press("q")
+    if !h.stopped() {
+        t.Fatal("q in the last tab did not quit")
+    }
+}
+
+// :wq with a file name closes only its tab, the others keep their changes
+func TestUIWriteQuitTab(t *testing.T) {
+    h := startUI(t, "people.csv", people, "towns.csv", "town;zip\nRome;001\n")
+    h.press("]", tcell.KeyDown)
+    h.edit("Oslo")
+    h.expectStatus("towns.csv [+]")
+
+    h.press("[", tcell.KeyDown)
+    h.edit("anna")
+    h.command("wq copy.csv")
+    if h.stopped() {
+        t.Fatal(":wq copy.csv quit with another tab open")
+    }
+    h.expectFile("copy.csv", "name,age,city\nanna,30,Rome\nbob,40,Oslo\ncarl,25,Lima\n")
+    h.expectFile("people.csv", people)
+    h.expectStatus("towns.csv [+]")
+    h.expectScreen("town")
+    h.expectScreen("Oslo")
+
+    h.command("wq")
+    if !h.stopped() {
+        t.Fatal(":wq in the last tab did not quit")
+    }
+    h.expectFile("towns.csv", "town;zip\nOslo;001\n")
+}
+
+func TestUIKeymap(t *testing.T) {
+    h := startUI(t, "people.csv", people)
+
+    // The global keymap file picks the vim preset, the one of the file adds a key
+    if err := os.Mkdir(filepath.Join(h.dir, "csvgo"), 0755); err != nil {
+        t.Fatal(err)
+    }
+    h.write("csvgo/keymap", "# mine\npreset = vim\n")
+    h.write("people.keymap", "X = clear_cell  # like x, without the clipboard\n")
+    h.command("e people.csv")
+    h.press("j", "l")
+    h.expectCursor(1, 1)
+    h.press("X", tcell.KeyCtrlS)
+    h.expectFile("people.csv", "name,age,city\nann,,Rome\nbob,40,Oslo\ncarl,25,Lima\n")
+
+    // Chords
+    h.press("G", "g")
+    h.expectCursor(3, 1)
+    h.expectStatus("g ...")
+    h.press("g")
+    h.expectCursor(0, 1)
+    h.press("d", "d")
+    h.expectScreen("Do you want to delete selected")
+    h.choose("No")
+    h.press("d", "z")
+    h.expectStatus("d z is not bound")
+
+    // A file with a conflict is not used
+    h.write("people.keymap", "Q = nothing\n")
+    h.command("e people.csv")
+    h.expectStatus(`Keymap not used: people.keymap:1: unknown action "nothing"`)
+    h.write("people.keymap", "g = undo\n")
+    h.command("e people.csv")
+    h.expectStatus("Keymap not used: people.keymap:1: g starts the chord g /")
+    h.press("j")
+    h.expectCursor(1, 0)
+
+    // :map and :set keymap
+    h.command("map Ctrl+E = last_row")
+    h.press(tcell.KeyCtrlE)
+    h.expectCursor(3, 0)
+    h.command("map g = undo")
+    h.expectStatus("g starts the chord")
+    h.command("set keymap=default")
+    h.press(tcell.KeyUp, "d")
+    h.expectScreen("Do you want to delete selected")
+    h.choose("No")
+}
+
+func TestUIHelp(t *testing.T) {
+    h := startUI(t, "people.csv", people)
+    h.press("h")
+    h.golden("help")
+
+    // Typing filters, the keys are the ones of the keymap
+    h.press("undo")
+    h.expectScreen("Ctrl+Z")
+    h.expectScreen(":undo :u")
+    h.press(tcell.KeyEscape)
+    h.command("map Ctrl+E = undo")
+    h.press("?", "undo")
+    h.expectScreen("Ctrl+E, Ctrl+Z")
+    h.press(tcell.KeyEscape)
+
+    h.command("helpexport help.csv")
+    h.expectStatus("help entries to help.csv")
+    if got := h.read("help.csv"); !strings.HasPrefix(got, "Kind,Keys,Name,Description\nkey,Right,move_right,") ||
+        !strings.Contains(got, "\ncommand,:helpexport <file>,helpexport,") {
+        t.Errorf("help.csv:\n%s", got)
+    }
+}
+
+func TestUITextEditor(t *testing.T) {
+    h := startUI(t, "notes.csv", "name,note\nann,\"line one\nline two\"\nbob,short\n")
+
+    // Cells with line breaks open in the text area, the text is selected
+    h.press(tcell.KeyDown, tcell.KeyRight, "e")
+    h.golden("text-editor")
+    h.press("first", tcell.KeyEnter, "second")
+    h.press(keyPress{tcell.KeyEnter, tcell.ModAlt})
+    h.press(tcell.KeyCtrlS)
+    h.expectFile("notes.csv", "name,note\nann,\"first\nsecond\"\nbob,short\n")
+
+    // Ctrl+Z undoes inside the editor, Esc asks before it drops changes
+    h.press("e", "x", tcell.KeyCtrlZ)
+    h.expectScreen("second")
+    h.press("y", tcell.KeyEscape)
+    h.expectScreen("Changes not saved")
+    h.press(tcell.KeyEscape)
+    h.expectScreen("bob")
+    h.expectStatus("notes.csv   row 1/2")
+
+    // Long cells use the text area as well, E opens it for any cell
+    h.command("set longcell=4")
+    h.press(tcell.KeyDown, "e")
+    h.expectScreen("Row 2, note")
+    h.press("tiny", tcell.KeyCtrlJ, tcell.KeyLeft, "E")
+    h.expectScreen("Row 2, name")
+    h.press(tcell.KeyEscape, tcell.KeyCtrlS)
+    h.expectFile("notes.csv", "name,note\nann,\"first\nsecond\"\nbob,tiny\n")
+
+    // One undo step in the table
+    h.press(tcell.KeyCtrlZ, tcell.KeyCtrlZ, tcell.KeyCtrlS)
+    h.expectFile("notes.csv", "name,note\nann,\"line one\nline two\"\nbob,short\n")
+}
+
+func TestUIMagnify(t *testing.T) {
+    h := startUI(t, "data.csv", "id,payload\n1,\"{\"\"name\"\":\"\"ann\"\",\"\"tags\"\":[\"\"a\"\"]}\"\n2,\"<a><b x=\"\"1\"\">text</b></a>\"\n")
+
+    // z shows the cell pretty printed, p switches to the raw text
+    h.press(tcell.KeyDown, tcell.KeyRight, "z")
+    h.golden("magnify")
+    h.press("p")
+    h.expectScreen(`{"name":"ann","tags":["a"]}`)
+    h.press("p")
+
+    // e edits the pretty text, a cell that was one line stays one line
+    h.press("e", "{", tcell.KeyEnter, `  "name": "bob"`, tcell.KeyEnter, "}", tcell.KeyCtrlJ)
+    h.expectScreen(`  "name": "bob"`)
+    h.press(tcell.KeyEscape, tcell.KeyCtrlS)
+    h.expectFile("data.csv", "id,payload\n1,\"{\"\"name\"\":\"\"bob\"\"}\"\n2,\"<a><b x=\"\"1\"\">text</b></a>\"\n")
+
+    h.press(tcell.KeyDown, "z")
+    h.expectScreen("Row 2, payload, XML")
+    h.expectScreen("║  <b x=\"1\">text</b>")
+    h.press(tcell.KeyEscape, tcell.KeyCtrlZ, tcell.KeyCtrlS)
+    h.expectFile("data.csv", "id,payload\n1,\"{\"\"name\"\":\"\"ann\"\",\"\"tags\"\":[\"\"a\"\"]}\"\n2,\"<a><b x=\"\"1\"\">text</b></a>\"\n")
+}
+
+func TestUIRecordForm(t *testing.T) {
+    h := startUI(t, "data.csv", "id,name,price\n1,apple,1.5\n2,pear,2\n")
+
+    // r shows the row as labelled fields, number columns only take numbers
+    h.press(tcell.KeyDown, "r")
+    h.golden("form")
+    h.press(tcell.KeyTab, tcell.KeyTab, tcell.KeyBackspace2, tcell.KeyBackspace2, tcell.KeyBackspace2, "abc", tcell.KeyPgDn)
+    h.expectScreen(`price: "abc" is not a number`)
+
+    // Going to the next record saves the row, one Ctrl+Z puts it back
+    h.press(tcell.KeyBackspace2, tcell.KeyBackspace2, tcell.KeyBackspace2, "9", tcell.KeyBacktab, "s", tcell.KeyPgDn)
+    h.expectScreen("Record 2 of 2 ( row 2 )")
+    h.press("x", tcell.KeyEscape)
+    h.expectScreen("Changes not saved")
+    h.press(tcell.KeyEscape, tcell.KeyCtrlS)
+    h.expectFile("data.csv", "id,name,price\n1,apples,9\n2,pear,2\n")
+    h.press(tcell.KeyCtrlZ, tcell.KeyCtrlS)
+    h.expectFile("data.csv", "id,name,price\n1,apple,1.5\n2,pear,2\n")
+}